@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/gethbin"
+)
+
+// detectedBinaryOption is the picker entry for the binary found on startup.
+const detectedBinaryOption = "(found on startup)"
+
+// binaries tracks the geth binary the current profile runs: the one
+// locateBinary found on startup, or one registered in the binary registry.
+type binaries struct {
+	registry  *gethbin.Registry
+	helpCache *gethbin.HelpCache
+	window    fyne.Window
+
+	// detected holds the gethbin.Binary found on startup and detectedStatus describes it
+	detected       binding.Untyped
+	detectedStatus binding.String
+	// label is the Binary field of the profile, empty for the detected binary
+	label binding.String
+	// active holds the gethbin.Binary the profile runs and status describes it
+	active binding.Untyped
+	status binding.String
+	// help holds the *gethbin.Help of the active binary, nil until it is loaded
+	help binding.Untyped
+
+	picker   *widget.Select
+	helpPath string
+}
+
+// newBinaries locates the geth binary GeNe was configured with and tracks
+// the binary selected by the profile from then on.
+func newBinaries(configured string, registry *gethbin.Registry, helpCache *gethbin.HelpCache, window fyne.Window) *binaries {
+	b := &binaries{
+		registry:       registry,
+		helpCache:      helpCache,
+		window:         window,
+		detected:       binding.NewUntyped(),
+		detectedStatus: binding.NewString(),
+		label:          binding.NewString(),
+		active:         binding.NewUntyped(),
+		status:         binding.NewString(),
+		help:           binding.NewUntyped(),
+	}
+	b.picker = widget.NewSelect(nil, func(option string) {
+		if option == detectedBinaryOption {
+			option = ""
+		}
+		b.label.Set(option)
+	})
+	b.reload()
+	b.picker.SetSelected(detectedBinaryOption)
+
+	locateBinary(configured, b.detected, b.detectedStatus)
+	listener := binding.NewDataListener(b.resolve)
+	b.detected.AddListener(listener)
+	b.detectedStatus.AddListener(listener)
+	b.label.AddListener(listener)
+	return b
+}
+
+// locateBinary finds the geth binary GeNe was configured with and probes its
+// version in the background. b holds the gethbin.Binary and status
+// describes it for the UI. When geth cannot be found the configured path, or
+// plain "geth", is kept so starting reports a meaningful error.
+func locateBinary(configured string, b binding.Untyped, status binding.String) {
+	path, err := gethbin.Locate(configured)
+	if err != nil {
+		fmt.Println(err)
+		status.Set(err.Error())
+		path = configured
+		if path == "" {
+			path = gethbin.Name
+		}
+		b.Set(gethbin.Binary{Path: path})
+		return
+	}
+
+	b.Set(gethbin.Binary{Path: path})
+	status.Set(fmt.Sprintf("geth: %s (probing version...)", path))
+	go func() {
+		v, err := gethbin.Probe(path)
+		if err != nil {
+			fmt.Println("Error probing geth version:", err)
+			status.Set(fmt.Sprintf("geth: %s (unknown version: %s)", path, err))
+			return
+		}
+		bin := gethbin.Binary{Path: path, Version: v}
+		b.Set(bin)
+		status.Set("geth: " + bin.String())
+	}()
+}
+
+// resolve updates the active binary after the profile or the detected binary changed.
+func (b *binaries) resolve() {
+	label, err := b.label.Get()
+	if err != nil {
+		fmt.Println("Error getting geth binary label")
+	}
+
+	var bin gethbin.Binary
+	if label == "" {
+		bin = currentBinary(b.detected)
+		status, _ := b.detectedStatus.Get()
+		b.status.Set(status)
+	} else if e, err := b.registry.Get(label); err != nil {
+		b.status.Set(err.Error())
+	} else {
+		bin = e.Binary()
+		b.status.Set(fmt.Sprintf("geth: %s, %s", label, bin))
+	}
+	b.active.Set(bin)
+
+	if bin.Path != "" && bin.Path != b.helpPath {
+		b.helpPath = bin.Path
+		b.help.Set((*gethbin.Help)(nil))
+		go loadHelp(b.helpCache, bin.Path, b.help)
+	}
+}
+
+// Path returns the executable to launch for the profile binary label,
+// verifying a registered binary has not been replaced since it was
+// registered. An empty label stands for the binary found on startup.
+func (b *binaries) Path(label string) (string, error) {
+	if label == "" {
+		return currentBinary(b.detected).Path, nil
+	}
+	e, err := b.registry.Resolve(label)
+	if err != nil {
+		return "", err
+	}
+	return e.Path, nil
+}
+
+// Get returns the profile binary label.
+func (b *binaries) Get() string {
+	label, err := b.label.Get()
+	if err != nil {
+		fmt.Println("Error getting geth binary label")
+	}
+	return label
+}
+
+// Set selects the binary with label, e.g. when a profile is loaded.
+func (b *binaries) Set(label string) {
+	if label == "" {
+		b.picker.SetSelected(detectedBinaryOption)
+	} else {
+		b.picker.SetSelected(label)
+	}
+	b.label.Set(label)
+}
+
+// reload refreshes the binaries offered by the picker.
+func (b *binaries) reload() {
+	entries, err := b.registry.List()
+	if err != nil {
+		dialog.ShowError(err, b.window)
+		return
+	}
+	options := []string{detectedBinaryOption}
+	for _, e := range entries {
+		options = append(options, e.Label)
+	}
+	b.picker.Options = options
+	b.picker.Refresh()
+}
+
+// newPicker builds the binary picker, with the status of the selected
+// binary and a button managing the registry.
+func (b *binaries) newPicker() fyne.CanvasObject {
+	status := widget.NewLabelWithData(b.status)
+	status.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(
+		widget.NewForm(widget.NewFormItem("geth binary", container.NewBorder(nil, nil, nil,
+			widget.NewButton("Manage binaries", b.manage), b.picker))),
+		status,
+	)
+}
+
+// manage shows the registered binaries and lets the user add and remove them.
+func (b *binaries) manage() {
+	rows := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		b.reload()
+		rows.RemoveAll()
+		entries, err := b.registry.List()
+		if err != nil {
+			dialog.ShowError(err, b.window)
+			return
+		}
+		if len(entries) == 0 {
+			rows.Add(widget.NewLabel("No binaries registered yet."))
+		}
+		for _, e := range entries {
+			e := e
+			info := widget.NewLabel(fmt.Sprintf("%s\tgeth %s\t%s\nSHA-256 %s", e.Label, e.Version, e.Path, e.SHA256))
+			remove := widget.NewButton("Remove", func() {
+				if err := b.registry.Remove(e.Label); err != nil {
+					dialog.ShowError(err, b.window)
+				}
+				refresh()
+				b.resolve()
+			})
+			rows.Add(container.NewBorder(nil, nil, nil, remove, info))
+		}
+	}
+	refresh()
+
+	add := widget.NewButton("Register binary", func() { b.register(refresh) })
+	content := container.NewBorder(nil, add, nil, nil, container.NewVScroll(rows))
+	d := dialog.NewCustom("geth binaries", "Close", content, b.window)
+	d.Resize(fyne.NewSize(900, 400))
+	d.Show()
+}
+
+// register asks for a label, a path and an optional checksum file, and
+// registers the binary once it matches the checksum.
+func (b *binaries) register(done func()) {
+	label := widget.NewEntry()
+	label.SetPlaceHolder("1.10.26")
+	path := widget.NewEntry()
+	path.SetPlaceHolder("/usr/local/bin/geth")
+	checksum := widget.NewEntry()
+	checksum.SetPlaceHolder("optional, in sha256sum format")
+
+	form := dialog.NewForm("Register geth binary", "Register", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Label", label),
+		widget.NewFormItem("Path", container.NewBorder(nil, nil, nil, b.browseButton(path), path)),
+		widget.NewFormItem("Checksum file", container.NewBorder(nil, nil, nil, b.browseButton(checksum), checksum)),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		// hashing and probing the binary can take a moment
+		go func() {
+			e, err := b.registry.Add(label.Text, path.Text, checksum.Text)
+			if err != nil {
+				dialog.ShowError(err, b.window)
+				return
+			}
+			done()
+			b.resolve()
+			msg := fmt.Sprintf("%s is geth %s.\nSHA-256 %s", e.Path, e.Version, e.SHA256)
+			if e.ChecksumFile != "" {
+				msg += "\nMatches " + e.ChecksumFile
+			}
+			dialog.ShowInformation("Registered "+e.Label, msg, b.window)
+		}()
+	}, b.window)
+	form.Resize(fyne.NewSize(700, 250))
+	form.Show()
+}
+
+// browseButton fills entry with a file picked by the user.
+func (b *binaries) browseButton(entry *widget.Entry) *widget.Button {
+	return widget.NewButton("Browse", func() {
+		dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, b.window)
+				return
+			}
+			if r == nil {
+				return
+			}
+			defer r.Close()
+			entry.SetText(r.URI().Path())
+		}, b.window)
+	})
+}
+
+// currentBinary returns the gethbin.Binary held by b.
+func currentBinary(b binding.Untyped) gethbin.Binary {
+	v, err := b.Get()
+	if err != nil {
+		fmt.Println("Error getting geth binary")
+	}
+	bin, _ := v.(gethbin.Binary)
+	return bin
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/gethargs"
+)
+
+// newImportCommandLineButton builds a button that asks for an existing geth
+// command line and loads its flags into the form.
+func newImportCommandLineButton(window fyne.Window, read func() config.UserInputForNodeConfig, write func(config.UserInputForNodeConfig)) fyne.CanvasObject {
+	return widget.NewButton("Import command line", func() {
+		entry := widget.NewMultiLineEntry()
+		entry.SetPlaceHolder("geth --datadir /var/lib/geth --http --http.api eth,net ...")
+		entry.SetMinRowsVisible(6)
+		entry.Validator = func(s string) error {
+			_, err := gethargs.Split(s)
+			return err
+		}
+
+		form := dialog.NewForm("Import command line", "Import", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Command", entry),
+		}, func(ok bool) {
+			if !ok {
+				return
+			}
+			p, err := gethargs.ParseCommandLine(entry.Text, read())
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			write(p.Config)
+			dialog.ShowInformation("Command line imported", importSummary(p), window)
+		}, window)
+		form.Resize(fyne.NewSize(800, 300))
+		form.Show()
+	})
+}
+
+// importSummary describes what ParseCommandLine did with each part of the command.
+func importSummary(p gethargs.Parsed) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Imported %d flags. Settings the command line does not set were reset to geth's defaults.", len(p.Recognised))
+	if len(p.Config.ExtraArgs) > 0 {
+		fmt.Fprintf(&b, "\n\nKept as extra arguments:\n%s", gethargs.QuoteCommand(p.Config.ExtraArgs))
+	}
+	if p.Binary != "" {
+		fmt.Fprintf(&b, "\n\nIgnored binary %s, GeNe runs the geth it is configured with.", p.Binary)
+	}
+	if len(p.Config.Env) > 0 {
+		fmt.Fprintf(&b, "\n\nSet environment: %s", strings.Join(config.Environ(p.Config.Env), " "))
+	}
+	return b.String()
+}
+
+// extraArgsFromText splits the extra arguments entry into words the way a
+// shell would. Text that does not split, e.g. with an unterminated quote,
+// falls back to one argument per line and is reported by the entry validator.
+func extraArgsFromText(s string) []string {
+	words, err := gethargs.Split(s)
+	if err != nil {
+		return splitLines(s)
+	}
+	return words
+}
+
+// splitLines returns the non-empty, trimmed lines of s.
+func splitLines(s string) []string {
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/node"
+)
+
+// nodeControls holds the node lifecycle actions and the bindings that
+// describe the node, so every tab can get its own toolbar bound to the same state.
+type nodeControls struct {
+	node   *node.Node
+	window fyne.Window
+
+	// state is the node.State of the node as a string
+	state binding.String
+	// status is the last exit status or error reported for the node
+	status binding.String
+	// crash summarises the last crash and what the restart policy did about it
+	crash binding.String
+	// crashOutput holds the last lines geth printed before it crashed
+	crashOutput binding.String
+
+	start   func()
+	stop    func()
+	restart func()
+}
+
+// newToolbar builds a Start/Stop/Restart toolbar with a status indicator.
+// Buttons are enabled according to the node state.
+func (c nodeControls) newToolbar() fyne.CanvasObject {
+	// starting runs `geth --help` and scans /proc, stopping waits for geth
+	// to shut down, keep them all off the UI goroutine
+	startButton := widget.NewButton("Start Geth", func() { go c.start() })
+	stopButton := widget.NewButton("Stop Geth", func() { go c.stop() })
+	restartButton := widget.NewButton("Restart Geth", func() { go c.restart() })
+
+	stateLabel := widget.NewLabelWithData(binding.NewSprintf("Status: %s", c.state))
+	stateLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	c.state.AddListener(binding.NewDataListener(func() {
+		s := c.node.State()
+		setEnabled(startButton, !s.Active())
+		// Stop also cancels the restart scheduled for a crashed node
+		setEnabled(stopButton, s == node.Starting || s == node.Ready || c.node.RestartPending())
+		setEnabled(restartButton, s != node.Starting && s != node.Stopping)
+	}))
+
+	crashLabel := widget.NewLabelWithData(c.crash)
+	crashLabel.Wrapping = fyne.TextWrapWord
+	crashOutputButton := widget.NewButton("Show crash output", func() {
+		out, _ := c.crashOutput.Get()
+		output := widget.NewLabel(out)
+		output.TextStyle = fyne.TextStyle{Monospace: true}
+		scroll := container.NewScroll(output)
+		scroll.SetMinSize(fyne.NewSize(800, 400))
+		dialog.ShowCustom("Last geth output", "Close", scroll, c.window)
+	})
+	crashOutputButton.Hide()
+
+	c.crash.AddListener(binding.NewDataListener(func() {
+		if s, _ := c.crash.Get(); s != "" {
+			crashOutputButton.Show()
+		}
+	}))
+
+	return container.NewVBox(
+		container.NewHBox(startButton, stopButton, restartButton, stateLabel),
+		widget.NewLabelWithData(c.status),
+		container.NewBorder(nil, nil, nil, crashOutputButton, crashLabel),
+	)
+}
+
+func setEnabled(w fyne.Disableable, enabled bool) {
+	if enabled {
+		w.Enable()
+	} else {
+		w.Disable()
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+)
+
+// envEditor edits the environment variables passed to geth as rows of
+// name and value entries, kept in sync with a binding holding []config.EnvVar.
+type envEditor struct {
+	vars binding.Untyped
+	rows *fyne.Container
+	// entries holds the name and value entry of every row, in order
+	entries [][2]*widget.Entry
+}
+
+// newEnvEditor builds the editor for vars.
+func newEnvEditor(vars binding.Untyped) fyne.CanvasObject {
+	e := &envEditor{vars: vars, rows: container.NewVBox()}
+	vars.AddListener(binding.NewDataListener(e.load))
+
+	add := widget.NewButtonWithIcon("Add variable", theme.ContentAddIcon(), func() {
+		e.addRow(config.EnvVar{})
+	})
+	return container.NewVBox(e.rows, container.NewHBox(add))
+}
+
+// envVars returns the variables held by vars.
+func envVars(vars binding.Untyped) []config.EnvVar {
+	v, err := vars.Get()
+	if err != nil {
+		fmt.Println("Error getting Environment variables")
+		return nil
+	}
+	env, _ := v.([]config.EnvVar)
+	return env
+}
+
+// current returns the variables entered in the rows, skipping empty rows.
+func (e *envEditor) current() []config.EnvVar {
+	var env []config.EnvVar
+	for _, row := range e.entries {
+		if row[0].Text == "" && row[1].Text == "" {
+			continue
+		}
+		env = append(env, config.EnvVar{Name: row[0].Text, Value: row[1].Text})
+	}
+	return env
+}
+
+// save writes the rows back to the binding.
+func (e *envEditor) save() {
+	e.vars.Set(e.current())
+}
+
+// load rebuilds the rows when the binding was changed from elsewhere, e.g.
+// by loading a profile. Changes made through the rows themselves are left
+// alone so the entry being typed in keeps its focus.
+func (e *envEditor) load() {
+	env := envVars(e.vars)
+	if len(env) == 0 && len(e.current()) == 0 || reflect.DeepEqual(env, e.current()) {
+		return
+	}
+	e.entries = nil
+	e.rows.RemoveAll()
+	for _, v := range env {
+		e.addRow(v)
+	}
+}
+
+func (e *envEditor) addRow(v config.EnvVar) {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("NAME")
+	name.SetText(v.Name)
+	name.OnChanged = func(string) { e.save() }
+
+	value := widget.NewEntry()
+	value.SetPlaceHolder("value")
+	value.SetText(v.Value)
+	value.OnChanged = func(string) { e.save() }
+
+	entries := [2]*widget.Entry{name, value}
+	var row *fyne.Container
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, r := range e.entries {
+			if r == entries {
+				e.entries = append(e.entries[:i], e.entries[i+1:]...)
+				break
+			}
+		}
+		e.rows.Remove(row)
+		e.save()
+	})
+	row = container.NewBorder(nil, nil, nil, remove, container.NewGridWithColumns(2, name, value))
+
+	e.entries = append(e.entries, entries)
+	e.rows.Add(row)
+}
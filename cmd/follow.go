@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/node"
+	"gene/internal/rpc"
+)
+
+// nodeFollower polls the node chosen in its picker over RPC while it is
+// Ready, keeping one connection open between polls.
+type nodeFollower struct {
+	manager  *nodeManager
+	interval time.Duration
+	// poll reads the node state, idle reports why the node cannot be polled
+	// and reset clears what was shown for the previous node
+	poll  func(ctx context.Context, client *rpc.Client, cfg config.UserInputForNodeConfig) error
+	idle  func(reason string)
+	reset func()
+
+	picker *widget.Select
+	wake   chan struct{}
+
+	mu     sync.Mutex
+	name   string
+	client *rpc.Client
+}
+
+// newNodeFollower builds a follower of the form node and starts polling.
+func newNodeFollower(manager *nodeManager, interval time.Duration,
+	poll func(ctx context.Context, client *rpc.Client, cfg config.UserInputForNodeConfig) error,
+	idle func(reason string), reset func()) *nodeFollower {
+	f := &nodeFollower{
+		manager:  manager,
+		interval: interval,
+		poll:     poll,
+		idle:     idle,
+		reset:    reset,
+		wake:     make(chan struct{}, 1),
+		name:     formNodeName,
+	}
+	f.picker = widget.NewSelect(manager.registry.Names(), func(name string) {
+		f.mu.Lock()
+		changed := name != f.name
+		f.name = name
+		f.mu.Unlock()
+		if changed {
+			f.disconnect()
+			f.reset()
+			f.refresh()
+		}
+	})
+	f.picker.SetSelected(formNodeName)
+	go f.run()
+	return f
+}
+
+// refresh polls the node right away instead of at the next tick.
+func (f *nodeFollower) refresh() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (f *nodeFollower) run() {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		f.tick()
+		select {
+		case <-ticker.C:
+		case <-f.wake:
+		}
+	}
+}
+
+func (f *nodeFollower) tick() {
+	if names := f.manager.registry.Names(); !equalStrings(names, f.picker.Options) {
+		f.picker.Options = names
+		f.picker.Refresh()
+	}
+
+	f.mu.Lock()
+	name := f.name
+	f.mu.Unlock()
+	n, ok := f.manager.registry.Get(name)
+	if !ok {
+		f.disconnect()
+		f.idle(fmt.Sprintf("%s: %s", name, node.ErrUnknownNode))
+		return
+	}
+	if s := n.State(); s != node.Ready {
+		f.disconnect()
+		f.idle(fmt.Sprintf("%s is %s", name, s))
+		return
+	}
+
+	cfg, _, err := f.manager.endpoint(name)
+	if err != nil {
+		f.idle(err.Error())
+		return
+	}
+	client, err := f.connect(name)
+	if err != nil {
+		f.idle(fmt.Sprintf("Error connecting to %s: %s", name, err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.interval)
+	defer cancel()
+	if err := f.poll(ctx, client, cfg); err != nil {
+		// dial again next time, geth may have been restarted
+		f.disconnect()
+		f.idle(fmt.Sprintf("Error polling %s: %s", name, err))
+	}
+}
+
+func (f *nodeFollower) connect(name string) (*rpc.Client, error) {
+	f.mu.Lock()
+	client := f.client
+	f.mu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+	client, err := f.manager.dial(name)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.client = client
+	f.mu.Unlock()
+	return client, nil
+}
+
+func (f *nodeFollower) disconnect() {
+	f.mu.Lock()
+	client := f.client
+	f.client = nil
+	f.mu.Unlock()
+	if client != nil {
+		client.Close()
+	}
+}
+
+// do runs fn against the followed node, for actions taken by the user,
+// and polls the node again afterwards.
+func (f *nodeFollower) do(fn func(ctx context.Context, client *rpc.Client) error) error {
+	client, err := f.connect(f.selected())
+	if err != nil {
+		return err
+	}
+	defer f.refresh()
+	ctx, cancel := context.WithTimeout(context.Background(), rpc.DefaultTimeout)
+	defer cancel()
+	return fn(ctx, client)
+}
+
+// selected returns the name of the followed node.
+func (f *nodeFollower) selected() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.name
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/gethargs"
+	"gene/internal/gethbin"
+)
+
+// flagForm is a single item form editing the config field behind one geth
+// flag. The original item is kept so it can be restored when the binary
+// changes to one that supports the flag again.
+type flagForm struct {
+	form        *widget.Form
+	item        *widget.FormItem
+	placeholder string
+}
+
+// flagForms tailors the form to the flags the geth binary supports, keyed
+// by config.UserInputForNodeConfig field.
+type flagForms map[string]*flagForm
+
+// add registers the form editing field.
+func (f flagForms) add(field string, form *widget.Form) {
+	ff := &flagForm{form: form, item: form.Items[0]}
+	if e, ok := ff.item.Widget.(*widget.Entry); ok {
+		ff.placeholder = e.PlaceHolder
+	}
+	f[field] = ff
+}
+
+// apply hides the fields whose flag h does not list and shows the real
+// default as placeholder and the real usage as hint on the others. A nil
+// h, e.g. while the help is loading, restores every field.
+func (f flagForms) apply(h *gethbin.Help) {
+	for field, ff := range f {
+		flag, _ := gethargs.FlagFor(field)
+		info, ok := h.Lookup(flag)
+		if h != nil && !ok {
+			ff.form.Items = nil
+			ff.form.Refresh()
+			continue
+		}
+
+		ff.item.HintText = info.Usage
+		if e, isEntry := ff.item.Widget.(*widget.Entry); isEntry {
+			if info.Default != "" {
+				e.SetPlaceHolder(info.Default)
+			} else {
+				e.SetPlaceHolder(ff.placeholder)
+			}
+		}
+		ff.form.Items = []*widget.FormItem{ff.item}
+		ff.form.Refresh()
+	}
+}
+
+// loadHelp reads the flags of the binary at path, through the cache, into b.
+func loadHelp(cache *gethbin.HelpCache, path string, b binding.Untyped) {
+	h, err := cache.Load(path)
+	if err != nil {
+		fmt.Println("Error reading geth flags:", err)
+		return
+	}
+	b.Set(h)
+}
+
+// currentHelp returns the *gethbin.Help held by b, nil until it is loaded.
+func currentHelp(b binding.Untyped) *gethbin.Help {
+	v, err := b.Get()
+	if err != nil {
+		fmt.Println("Error getting geth flags")
+	}
+	h, _ := v.(*gethbin.Help)
+	return h
+}
+
+// dropUnsupported clears the fields of cfg whose flag h does not list, which
+// apply hides from the form, so geth is not started with a flag it does not
+// define. Without a help, e.g. while it is loading, cfg is kept as is.
+func dropUnsupported(h *gethbin.Help, cfg config.UserInputForNodeConfig) (config.UserInputForNodeConfig, []gethargs.Mapping) {
+	if h == nil {
+		return cfg, nil
+	}
+	return gethargs.DropUnsupported(cfg, h.Supports)
+}
+
+// droppedFlags lists the flags of dropped, e.g. "--miner.gastarget, --txlookuplimit".
+func droppedFlags(dropped []gethargs.Mapping) string {
+	flags := make([]string, len(dropped))
+	for i, m := range dropped {
+		flags[i] = "--" + m.Flag
+	}
+	return strings.Join(flags, ", ")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/gethlog"
+)
+
+// logRefreshInterval limits how often the log view redraws while geth is busy.
+const logRefreshInterval = 250 * time.Millisecond
+
+// logViewer shows the lines of a gethlog.Buffer, filtered by level and substring.
+type logViewer struct {
+	logs *gethlog.Buffer
+
+	mu         sync.Mutex
+	minLevel   gethlog.Level
+	filter     string
+	paused     bool
+	autoScroll bool
+	dirty      bool
+	shown      []gethlog.Line
+
+	list *widget.List
+}
+
+// newLogsTab builds the "Logs" tab for the given buffer.
+func newLogsTab(logs *gethlog.Buffer) fyne.CanvasObject {
+	v := &logViewer{
+		logs:       logs,
+		autoScroll: true,
+		dirty:      true,
+	}
+
+	v.list = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.shown)
+		},
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.TextStyle = fyne.TextStyle{Monospace: true}
+			return l
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			if id < len(v.shown) {
+				o.(*widget.Label).SetText(v.shown[id].Text)
+			}
+		},
+	)
+
+	levels := []string{"ALL"}
+	for _, l := range gethlog.Levels {
+		levels = append(levels, l.String())
+	}
+	levelSelect := widget.NewSelect(levels, func(s string) {
+		v.mu.Lock()
+		v.minLevel = gethlog.ParseLevel(s)
+		v.dirty = true
+		v.mu.Unlock()
+		v.refresh()
+	})
+	levelSelect.SetSelected("ALL")
+
+	filterEntry := widget.NewEntry()
+	filterEntry.SetPlaceHolder("Filter")
+	filterEntry.OnChanged = func(s string) {
+		v.mu.Lock()
+		v.filter = s
+		v.dirty = true
+		v.mu.Unlock()
+		v.refresh()
+	}
+
+	autoScrollCheck := widget.NewCheck("Auto-scroll", func(b bool) {
+		v.mu.Lock()
+		v.autoScroll = b
+		v.mu.Unlock()
+	})
+	autoScrollCheck.SetChecked(true)
+
+	pauseCheck := widget.NewCheck("Pause", func(b bool) {
+		v.mu.Lock()
+		v.paused = b
+		v.dirty = true
+		v.mu.Unlock()
+		v.refresh()
+	})
+
+	clearButton := widget.NewButton("Clear", func() {
+		v.logs.Clear()
+		v.mu.Lock()
+		v.dirty = true
+		v.mu.Unlock()
+		v.refresh()
+	})
+
+	logs.Subscribe(func(gethlog.Line) {
+		v.mu.Lock()
+		v.dirty = true
+		v.mu.Unlock()
+	})
+
+	// redraw at most every logRefreshInterval rather than once per line
+	go func() {
+		for range time.Tick(logRefreshInterval) {
+			v.refresh()
+		}
+	}()
+
+	toolbar := container.NewBorder(nil, nil,
+		container.NewHBox(widget.NewLabel("Level"), levelSelect),
+		container.NewHBox(autoScrollCheck, pauseCheck, clearButton),
+		filterEntry,
+	)
+	return container.NewBorder(toolbar, nil, nil, nil, v.list)
+}
+
+// refresh re-applies the filters and redraws the list if anything changed.
+func (v *logViewer) refresh() {
+	v.mu.Lock()
+	if !v.dirty || v.paused {
+		v.mu.Unlock()
+		return
+	}
+	v.dirty = false
+	minLevel, filter, autoScroll := v.minLevel, strings.ToLower(v.filter), v.autoScroll
+	v.mu.Unlock()
+
+	var shown []gethlog.Line
+	for _, l := range v.logs.Lines() {
+		if minLevel != gethlog.LevelUnknown && l.Level < minLevel {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(l.Text), filter) {
+			continue
+		}
+		shown = append(shown, l)
+	}
+
+	v.mu.Lock()
+	v.shown = shown
+	v.mu.Unlock()
+
+	v.list.Refresh()
+	if autoScroll {
+		v.list.ScrollToBottom()
+	}
+}
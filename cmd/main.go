@@ -1,16 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"os"
-	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 
 	//"fyne.io/fyne/v2/theme"
@@ -18,88 +20,20 @@ import (
 	// geth "github.com/ethereum/go-ethereum"
 
 	"github.com/kelseyhightower/envconfig"
+
+	"gene/internal/config"
+	"gene/internal/datadir"
+	"gene/internal/gethargs"
+	"gene/internal/gethbin"
+	"gene/internal/node"
+	"gene/internal/ports"
 )
 
 type envConfig struct {
-	// GethFileLocation is the location of the geth binary
-	GethFileLocation string `env:"GETH" default:"../geth"`
-}
-
-type Node struct {
-	GethFileLocation string
-}
-
-type UserInputForNodeConfig struct {
-	// TOMLConfig points to a TOML configuration file
-	TOMLConfig string
-
-	// RPCHTTPPort HTTP-RPC server listening port (default: 8545)
-	RPCHTTPPort string
-	// RPCHTTPSelectedAPIMethods HTTP-RPC API modules
-	RPCHTTPSelectedAPIMethods []string
-
-	// WSRPCInterface WS-RPC server listening interface (default: "localhost")
-	WSRPCInterface string
-	// WSRPCHTTPPort WS-RPC server listening port (default: 8546)
-	WSRPCHTTPPort string
-	// WSRPCOrigins WS-RPC allowed origins list (default: "[]")
-	WSRPCOrigins string
-	// WSRPCAPIs API's offered over the WS-RPC interface
-	WSRPCAPIs []string
-
-	// GraphQLEnabled Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.
-	GraphQLEnabled bool
-	// GraphQLCors Comma separated list of domains from which to accept cross origin requests (browser enforced)
-	GraphQLCors string
-	// GraphQLVirtualHosts Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard. (default: "localhost")
-	GraphQLVirtualHosts string
-
-	// AdminAddr Listening address for authenticated APIs (default: "localhost")
-	AdminAddr string
-	// AdminPort Listening port for authenticated APIs (default: 8551)
-	AdminPort string
-
-	// PreloadJS Comma separated list of JavaScript files to preload into the console
-	PreloadJS string
-	// ExecJS  Execute JavaScript statement
-	ExecJS string
-
-	// DBEndpoint URL for remote database
-	DBEndpoint string
-	// TxLookupLimit Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain) (default: 2350000)
-	TxLookupLimit string
-	// SyncMode Blockchain sync mode ("snap", "full" or "light") (default: snap)
-	SyncMode string
-	// NetworkID Explicitly set network id (integer)(For testnets: use --sepolia, --goerli instead) (default: 1)
-	NetworkID string
-	// P2PPort Network listening port (default: 30303)
-	P2PPort string
-	// DataDir Data directory for the databases and keystore (default: "~/.ethereum")
-	DataDir string
-
-	// UserAddress Public address for block mining rewards (default = first account) (default: "0")
-	UserAddress string
-	// MinerThreads Number of CPU threads to use for mining (default: 0 = use all available cores)
-	MinerThreads string
-	// NotifyURLs Comma separated HTTP URL list to notify of new work packages
-	NotifyURLs string
-	// MinerMinimumGasPrice Minimum gas price for mining a transaction (default: 1000000000)
-	MinerMinimumGasPrice string
-	// MinerGasTarget Target gas ceiling for mined blocks (default: 30000000)
-	MinerGasTarget string
-	// MinerExtraData Block extra data set by the miner (default = client version)
-	MinerExtraData string
-	// MinerRecommit Time interval to recreate the block being mined (default: 3s)
-	MinerRecommit string
-	// MinerDisableRemoteSealing Disable remote sealing verification
-	MinerDisableRemoteSealing bool
-
-	// DeveloperMode Flag to enable Ephemeral proof-of-authority network with a pre-funded developer account, mining enabled
-	DeveloperMode bool
-	// DeveloperPeriod Block period to use in developer mode (0 = mine only if transaction pending) (default: 0)
-	DeveloperPeriod string
-	// DeveloperGasLimit Initial block gas limit (default: 11500000)
-	DeveloperGasLimit string
+	// GethFileLocation is the location of the geth binary, searched for when empty
+	GethFileLocation string `envconfig:"GETH"`
+	// StopTimeout is how long to wait for geth to exit after SIGINT before sending SIGKILL
+	StopTimeout time.Duration `envconfig:"GETH_STOP_TIMEOUT" default:"30s"`
 }
 
 func main() {
@@ -110,30 +44,121 @@ func main() {
 	}
 
 	myApp := app.New()
-	n := &Node{
-		GethFileLocation: cfg.GethFileLocation,
-	}
 
 	myWindow := myApp.NewWindow("GeNe")
 	myWindow.Resize(fyne.NewSize(1080, 1080))
 
+	// the registered geth binaries and the flags each supports, read from
+	// its --help once per binary
+	registry, err := gethbin.DefaultRegistry()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	helpCache, err := gethbin.DefaultHelpCache()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	bins := newBinaries(cfg.GethFileLocation, registry, helpCache, myWindow)
+
+	profiles, err := config.DefaultProfileStore()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	nodeStore, err := config.DefaultNodeStore()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// the node edited in the config tabs, run next to the ones in the Nodes tab
+	n := &node.Node{
+		GethFileLocation: currentBinary(bins.detected).Path,
+		StopTimeout:      cfg.StopTimeout,
+	}
+	nodes := &node.Registry{}
+	if err := nodes.Add(formNodeName, n); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// report how the geth process ended back to the UI
+	nodeExitBinding := binding.NewString()
+	n.OnExit = func(s node.ExitStatus) {
+		fmt.Println(s)
+		nodeExitBinding.Set(s.String())
+	}
+
+	// surface crashes and crash loops, with the last lines geth printed
+	nodeCrashBinding := binding.NewString()
+	nodeCrashOutputBinding := binding.NewString()
+	n.OnCrash = func(r node.CrashReport) {
+		fmt.Println(r)
+		nodeCrashBinding.Set(r.String())
+		nodeCrashOutputBinding.Set(strings.Join(r.Status.LastLines, "\n"))
+	}
+	n.OnNotReady = func(err *node.ReadyError) {
+		fmt.Println(err)
+		nodeCrashBinding.Set(err.Error())
+		nodeCrashOutputBinding.Set(strings.Join(err.LastLines, "\n"))
+	}
+
+	// mirror the node lifecycle state into a binding shared by every tab
+	nodeStateBinding := binding.NewString()
+	nodeStateBinding.Set(n.State().String())
+	n.AddStateListener(func(s node.State) {
+		nodeStateBinding.Set(s.String())
+	})
+
+	// entries checked by config.Validate, keyed by config.UserInputForNodeConfig field
+	validatedEntries := make(map[string]*widget.Entry)
+	validatedEntry := func(field string, b binding.String) *widget.Entry {
+		e := widget.NewEntryWithData(b)
+		validatedEntries[field] = e
+		return e
+	}
+
 	tomlConfigBinding := binding.NewString()
 	tomlConfigInput := widget.NewForm(
 		widget.NewFormItem("TOML config file location", widget.NewEntryWithData(tomlConfigBinding)),
 	)
 
+	httpEnabledBinding := binding.NewBool()
+	httpEnabledInput := widget.NewForm(
+		widget.NewFormItem("Enable the HTTP-RPC server", widget.NewCheckWithData("HTTP Enabled", httpEnabledBinding)),
+	)
+
+	httpAddrBinding := binding.NewString()
+	httpAddrInput := widget.NewForm(
+		widget.NewFormItem("HTTP-RPC server listening interface (default: localhost)", widget.NewEntryWithData(httpAddrBinding)),
+	)
+
+	httpCorsDomainBinding := binding.NewString()
+	httpCorsDomainInput := widget.NewForm(
+		widget.NewFormItem("Comma separated list of domains from which to accept cross origin requests to the HTTP-RPC server (browser enforced)", widget.NewEntryWithData(httpCorsDomainBinding)),
+	)
+
+	httpVirtualHostsBinding := binding.NewString()
+	httpVirtualHostsInput := widget.NewForm(
+		widget.NewFormItem("Comma separated list of virtual hostnames from which to accept HTTP-RPC requests (server enforced). Accepts '*' wildcard. (default: localhost)", widget.NewEntryWithData(httpVirtualHostsBinding)),
+	)
+
+	wsEnabledBinding := binding.NewBool()
+	wsEnabledInput := widget.NewForm(
+		widget.NewFormItem("Enable the WS-RPC server", widget.NewCheckWithData("WS Enabled", wsEnabledBinding)),
+	)
+
 	rpcHTTPPortBinding := binding.NewString()
 	rpcPortInput := widget.NewForm(
-		widget.NewFormItem("HTTP-RPC server listening port (default: 8545)", widget.NewEntryWithData(rpcHTTPPortBinding)),
+		widget.NewFormItem("HTTP-RPC server listening port (default: 8545)", validatedEntry("RPCHTTPPort", rpcHTTPPortBinding)),
 	)
 
-	httpAPIMethods := []string{"eth", "net", "web3", "txpool", "debug", "admin", "miner", "shh", "clique", "les"}
+	// the options follow the help of the geth binary, see the bins.help listener below
+	httpAPIMethods := gethbin.AvailableAPIs(nil, gethbin.Version{})
 	RPCHTTPSelectedAPIMethods := binding.NewStringList()
 	// select from a list of methods "eth,net,web3,txpool,debug,admin,miner,shh,clique,les"
-	httpAPIMethodsInput := widget.NewForm(widget.NewFormItem("API's offered over the HTTP-RPC interface", widget.NewCheckGroup(httpAPIMethods, func(s []string) {
+	httpAPIMethodsCheck := widget.NewCheckGroup(httpAPIMethods, func(s []string) {
 		fmt.Println("Selected:", s)
 		RPCHTTPSelectedAPIMethods.Set(s)
-	})))
+	})
+	httpAPIMethodsInput := widget.NewForm(widget.NewFormItem("API's offered over the HTTP-RPC interface", httpAPIMethodsCheck))
 
 	httpAPIMethodsInput.Hide()
 
@@ -144,7 +169,7 @@ func main() {
 
 	wsRPCHTTPPortBinding := binding.NewString()
 	wsRPCHTTPPortInput := widget.NewForm(
-		widget.NewFormItem("WS-RPC server listening port (default: 8546)", widget.NewEntryWithData(wsRPCHTTPPortBinding)),
+		widget.NewFormItem("WS-RPC server listening port (default: 8546)", validatedEntry("WSRPCHTTPPort", wsRPCHTTPPortBinding)),
 	)
 
 	wsRPCOriginsBinding := binding.NewString()
@@ -158,10 +183,11 @@ func main() {
 	)
 
 	WSRPCAPIsBinding := binding.NewStringList()
-	WSRPCAPIsInput := widget.NewForm(widget.NewFormItem("API's offered over the WS-RPC interface", widget.NewCheckGroup(httpAPIMethods, func(s []string) {
+	WSRPCAPIsCheck := widget.NewCheckGroup(httpAPIMethods, func(s []string) {
 		fmt.Println("Selected:", s)
-		RPCHTTPSelectedAPIMethods.Set(s)
-	})))
+		WSRPCAPIsBinding.Set(s)
+	})
+	WSRPCAPIsInput := widget.NewForm(widget.NewFormItem("API's offered over the WS-RPC interface", WSRPCAPIsCheck))
 
 	WSRPCAPIsInput.Hide()
 
@@ -187,7 +213,7 @@ func main() {
 
 	adminPortBinding := binding.NewString()
 	adminPortInput := widget.NewForm(
-		widget.NewFormItem("Listening port for authenticated APIs (default: 8551)", widget.NewEntryWithData(adminPortBinding)),
+		widget.NewFormItem("Listening port for authenticated APIs (default: 8551)", validatedEntry("AdminPort", adminPortBinding)),
 	)
 
 	preloadJSBinding := binding.NewString()
@@ -207,22 +233,22 @@ func main() {
 
 	txLookupLimitBinding := binding.NewString()
 	txLookupLimitInput := widget.NewForm(
-		widget.NewFormItem("Number of recent transactions to maintain in the local transaction history (default: 128)", widget.NewEntryWithData(txLookupLimitBinding)),
+		widget.NewFormItem("Number of recent transactions to maintain in the local transaction history (default: 128)", validatedEntry("TxLookupLimit", txLookupLimitBinding)),
 	)
 
 	syncModeBinding := binding.NewString()
 	syncModeInput := widget.NewForm(
-		widget.NewFormItem("Blockchain sync mode ('fast', 'full', or 'light')", widget.NewEntryWithData(syncModeBinding)),
+		widget.NewFormItem("Blockchain sync mode ('snap', 'full', or 'light')", validatedEntry("SyncMode", syncModeBinding)),
 	)
 
 	networkIDBinding := binding.NewString()
 	networkIDInput := widget.NewForm(
-		widget.NewFormItem("Network identifier (Chain ID)", widget.NewEntryWithData(networkIDBinding)),
+		widget.NewFormItem("Network identifier (Chain ID)", validatedEntry("NetworkID", networkIDBinding)),
 	)
 
 	p2pPortBinding := binding.NewString()
 	p2pPortInput := widget.NewForm(
-		widget.NewFormItem("Network listening port (default: 30303)", widget.NewEntryWithData(p2pPortBinding)),
+		widget.NewFormItem("Network listening port (default: 30303)", validatedEntry("P2PPort", p2pPortBinding)),
 	)
 
 	dataDirBinding := binding.NewString()
@@ -230,14 +256,24 @@ func main() {
 		widget.NewFormItem("Data directory for the databases and keystore", widget.NewEntryWithData(dataDirBinding)),
 	)
 
+	ipcDisableBinding := binding.NewBool()
+	ipcDisableInput := widget.NewForm(
+		widget.NewFormItem("Disable the IPC-RPC server, GeNe then reaches geth over HTTP", widget.NewCheckWithData("Disable", ipcDisableBinding)),
+	)
+
+	ipcPathBinding := binding.NewString()
+	ipcPathInput := widget.NewForm(
+		widget.NewFormItem("Filename for IPC socket/pipe within the datadir (explicit paths escape it) (default: geth.ipc)", widget.NewEntryWithData(ipcPathBinding)),
+	)
+
 	userAddressBinding := binding.NewString()
 	userAddressInput := widget.NewForm(
-		widget.NewFormItem("Public address of the signing key", widget.NewEntryWithData(userAddressBinding)),
+		widget.NewFormItem("Public address of the signing key", validatedEntry("UserAddress", userAddressBinding)),
 	)
 
 	minerThreadsBinding := binding.NewString()
 	minerThreadsInput := widget.NewForm(
-		widget.NewFormItem("Number of CPU threads to use for mining (default: 0)", widget.NewEntryWithData(minerThreadsBinding)),
+		widget.NewFormItem("Number of CPU threads to use for mining (default: 0)", validatedEntry("MinerThreads", minerThreadsBinding)),
 	)
 
 	notifyURLsBinding := binding.NewString()
@@ -247,12 +283,17 @@ func main() {
 
 	minerMinimumGasPriceBinding := binding.NewString()
 	minerMinimumGasPriceInput := widget.NewForm(
-		widget.NewFormItem("Minimum accepted gas price to allow mining a transaction (default: 18000000000)", widget.NewEntryWithData(minerMinimumGasPriceBinding)),
+		widget.NewFormItem("Minimum accepted gas price to allow mining a transaction (default: 18000000000)", validatedEntry("MinerMinimumGasPrice", minerMinimumGasPriceBinding)),
 	)
 
 	minerGasTargetBinding := binding.NewString()
 	minerGasTargetInput := widget.NewForm(
-		widget.NewFormItem("Target gas floor for mined blocks ", widget.NewEntryWithData(minerGasTargetBinding)),
+		widget.NewFormItem("Target gas floor for mined blocks ", validatedEntry("MinerGasTarget", minerGasTargetBinding)),
+	)
+
+	minerGasLimitBinding := binding.NewString()
+	minerGasLimitInput := widget.NewForm(
+		widget.NewFormItem("Target gas ceiling for mined blocks", validatedEntry("MinerGasLimit", minerGasLimitBinding)),
 	)
 
 	minerExtraDataBinding := binding.NewString()
@@ -262,7 +303,7 @@ func main() {
 
 	minerRecommitBinding := binding.NewString()
 	minerRecommitInput := widget.NewForm(
-		widget.NewFormItem("Time interval to recreate the block mining work", widget.NewEntryWithData(minerRecommitBinding)),
+		widget.NewFormItem("Time interval to recreate the block mining work", validatedEntry("MinerRecommit", minerRecommitBinding)),
 	)
 
 	minerNoverifyBinding := binding.NewBool()
@@ -277,12 +318,52 @@ func main() {
 
 	devPeriodBinding := binding.NewString()
 	devPeriodInput := widget.NewForm(
-		widget.NewFormItem("Block period to use in developer mode (0 = mine only if transaction pending)", widget.NewEntryWithData(devPeriodBinding)),
+		widget.NewFormItem("Block period to use in developer mode (0 = mine only if transaction pending)", validatedEntry("DeveloperPeriod", devPeriodBinding)),
 	)
 
 	devGasLimitBinding := binding.NewString()
 	devGasLimitInput := widget.NewForm(
-		widget.NewFormItem("Target gas limit to enforce in developer mode", widget.NewEntryWithData(devGasLimitBinding)),
+		widget.NewFormItem("Target gas limit to enforce in developer mode", validatedEntry("DeveloperGasLimit", devGasLimitBinding)),
+	)
+
+	extraArgsBinding := binding.NewString()
+	extraArgsEntry := widget.NewEntryWithData(extraArgsBinding)
+	extraArgsEntry.MultiLine = true
+	extraArgsInput := widget.NewForm(
+		widget.NewFormItem("Extra geth flags, one per line with their value, appended as is", extraArgsEntry),
+	)
+
+	envBinding := binding.NewUntyped()
+	envBinding.Set([]config.EnvVar(nil))
+	envInput := widget.NewForm(
+		widget.NewFormItem("Environment variables for the geth process", newEnvEditor(envBinding)),
+	)
+
+	restartPolicies := make([]string, len(node.RestartPolicies))
+	for i, p := range node.RestartPolicies {
+		restartPolicies[i] = string(p)
+	}
+	restartPolicyBinding := binding.NewString()
+	restartPolicySelect := widget.NewSelect(restartPolicies, func(s string) {
+		restartPolicyBinding.Set(s)
+	})
+	restartPolicyInput := widget.NewForm(
+		widget.NewFormItem("Restart geth when it exits unexpectedly (default: never)", restartPolicySelect),
+	)
+
+	maxRestartsBinding := binding.NewString()
+	maxRestartsInput := widget.NewForm(
+		widget.NewFormItem("Automatic restarts allowed within the restart window before giving up (default: 5)", validatedEntry("MaxRestarts", maxRestartsBinding)),
+	)
+
+	restartWindowBinding := binding.NewString()
+	restartWindowInput := widget.NewForm(
+		widget.NewFormItem("Period over which automatic restarts are counted (default: 10m)", validatedEntry("RestartWindow", restartWindowBinding)),
+	)
+
+	readyTimeoutBinding := binding.NewString()
+	readyTimeoutInput := widget.NewForm(
+		widget.NewFormItem("How long to wait for geth to answer on its IPC or HTTP endpoint after launch (default: 1m)", validatedEntry("ReadyTimeout", readyTimeoutBinding)),
 	)
 
 	// create a binding.DataListener to listen for changes to the graphQLEnabledInput
@@ -310,6 +391,9 @@ func main() {
 		if r {
 			WSRPCAPIsInput.Show()
 			httpAPIMethodsInput.Show()
+			httpAddrInput.Hide()
+			httpCorsDomainInput.Hide()
+			httpVirtualHostsInput.Hide()
 			wsRPCHTTPPortInput.Hide()
 			wsRPCOriginsInput.Hide()
 			graphQLEnabledInput.Hide()
@@ -323,11 +407,21 @@ func main() {
 			networkIDInput.Hide()
 			p2pPortInput.Hide()
 			dataDirInput.Hide()
+			ipcDisableInput.Hide()
+			ipcPathInput.Hide()
+			extraArgsInput.Hide()
+			restartPolicyInput.Hide()
+			maxRestartsInput.Hide()
+			restartWindowInput.Hide()
+			readyTimeoutInput.Hide()
 
 		} else {
 			WSRPCAPIsInput.Hide()
 			httpAPIMethodsInput.Hide()
 
+			httpAddrInput.Show()
+			httpCorsDomainInput.Show()
+			httpVirtualHostsInput.Show()
 			wsRPCHTTPPortInput.Show()
 			wsRPCOriginsInput.Show()
 			graphQLEnabledInput.Show()
@@ -341,6 +435,13 @@ func main() {
 			networkIDInput.Show()
 			p2pPortInput.Show()
 			dataDirInput.Show()
+			ipcDisableInput.Show()
+			ipcPathInput.Show()
+			extraArgsInput.Show()
+			restartPolicyInput.Show()
+			maxRestartsInput.Show()
+			restartWindowInput.Show()
+			readyTimeoutInput.Show()
 
 		}
 	})
@@ -348,13 +449,38 @@ func main() {
 	APIsToogleBinding.AddListener(dl)
 	graphQLEnabledBinding.AddListener(graphQLDL)
 
-	startGethButton := widget.NewButton("Start Geth", func() {
-		myWindow.SetTitle("Start Geth")
+	// readUserInput collects the current value of every form binding
+	readUserInput := func() config.UserInputForNodeConfig {
 		tomlConfig, err := tomlConfigBinding.Get()
 		if err != nil {
 			fmt.Println("Error getting TOML config file location")
 		}
 
+		httpEnabled, err := httpEnabledBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting HTTP-RPC server enabled")
+		}
+
+		httpAddr, err := httpAddrBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting HTTP-RPC server listening interface")
+		}
+
+		httpCorsDomain, err := httpCorsDomainBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting HTTP-RPC CORS domains")
+		}
+
+		httpVirtualHosts, err := httpVirtualHostsBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting HTTP-RPC virtual hosts")
+		}
+
+		wsEnabled, err := wsEnabledBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting WS-RPC server enabled")
+		}
+
 		httpRPCPort, err := rpcHTTPPortBinding.Get()
 		if err != nil {
 			fmt.Println("Error getting HTTP-RPC server listening port")
@@ -375,6 +501,11 @@ func main() {
 			fmt.Println("Error getting WS-RPC server listening port")
 		}
 
+		wsRPCOrigins, err := wsRPCOriginsBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting WS-RPC allowed origins list")
+		}
+
 		// create a toggle to hide/show the WS-RPC API's
 		// toggle, err := APIsToogleBinding.Get()
 		// if err != nil {
@@ -451,6 +582,16 @@ func main() {
 			fmt.Println("Error getting Data directory for the databases and keystore")
 		}
 
+		ipcDisable, err := ipcDisableBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Disable the IPC-RPC server")
+		}
+
+		ipcPath, err := ipcPathBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Filename for IPC socket")
+		}
+
 		userAddress, err := userAddressBinding.Get()
 		if err != nil {
 			fmt.Println("Error getting Ethereum address for the signing and the mining")
@@ -476,6 +617,11 @@ func main() {
 			fmt.Println("Error getting Target gas floor for mined blocks ")
 		}
 
+		minerGasLimit, err := minerGasLimitBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Target gas ceiling for mined blocks")
+		}
+
 		minerExtraData, err := minerExtraDataBinding.Get()
 		if err != nil {
 			fmt.Println("Error getting Specify a custom extra-data for block headers")
@@ -506,14 +652,45 @@ func main() {
 			fmt.Println("Error getting Target gas floor for mined blocks ")
 		}
 
-		ui := UserInputForNodeConfig{
+		extraArgs, err := extraArgsBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Extra geth arguments")
+		}
+
+		restartPolicy, err := restartPolicyBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Restart policy")
+		}
+
+		maxRestarts, err := maxRestartsBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Max restarts")
+		}
+
+		restartWindow, err := restartWindowBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Restart window")
+		}
+
+		readyTimeout, err := readyTimeoutBinding.Get()
+		if err != nil {
+			fmt.Println("Error getting Ready timeout")
+		}
+
+		return config.UserInputForNodeConfig{
 			TOMLConfig: tomlConfig,
 
+			HTTPEnabled:               httpEnabled,
+			HTTPAddr:                  httpAddr,
 			RPCHTTPPort:               httpRPCPort,
 			RPCHTTPSelectedAPIMethods: s,
+			HTTPCorsDomain:            httpCorsDomain,
+			HTTPVirtualHosts:          httpVirtualHosts,
 
+			WSEnabled:      wsEnabled,
 			WSRPCInterface: wsRPCInterface,
 			WSRPCHTTPPort:  wsRPCHTTPPort,
+			WSRPCOrigins:   wsRPCOrigins,
 			WSRPCAPIs:      wsRPCAPIs,
 
 			GraphQLEnabled:      graphQLEnabled,
@@ -532,12 +709,15 @@ func main() {
 			NetworkID:     networkID,
 			P2PPort:       p2pPort,
 			DataDir:       dataDir,
+			IPCDisable:    ipcDisable,
+			IPCPath:       ipcPath,
 
 			UserAddress:               userAddress,
 			MinerThreads:              minerThreads,
 			NotifyURLs:                notifyURLs,
 			MinerMinimumGasPrice:      minerMinimumGasPrice,
 			MinerGasTarget:            minerGasTarget,
+			MinerGasLimit:             minerGasLimit,
 			MinerExtraData:            minerExtraData,
 			MinerRecommit:             minerRecommitInterval,
 			MinerDisableRemoteSealing: minerNoverify,
@@ -545,23 +725,182 @@ func main() {
 			DeveloperMode:     devMode,
 			DeveloperPeriod:   devPeriod,
 			DeveloperGasLimit: devGasLimit,
+
+			ExtraArgs: extraArgsFromText(extraArgs),
+			Env:       envVars(envBinding),
+
+			RestartPolicy: restartPolicy,
+			MaxRestarts:   maxRestarts,
+			RestartWindow: restartWindow,
+			ReadyTimeout:  readyTimeout,
+
+			Binary: bins.Get(),
 		}
+	}
 
-		n.startGeth(ui)
-	})
+	// writeUserInput fills every form binding from cfg, e.g. when a profile is loaded
+	writeUserInput := func(cfg config.UserInputForNodeConfig) {
+		tomlConfigBinding.Set(cfg.TOMLConfig)
+
+		httpEnabledBinding.Set(cfg.HTTPEnabled)
+		httpAddrBinding.Set(cfg.HTTPAddr)
+		rpcHTTPPortBinding.Set(cfg.RPCHTTPPort)
+		httpAPIMethodsCheck.SetSelected(cfg.RPCHTTPSelectedAPIMethods)
+		RPCHTTPSelectedAPIMethods.Set(cfg.RPCHTTPSelectedAPIMethods)
+		httpCorsDomainBinding.Set(cfg.HTTPCorsDomain)
+		httpVirtualHostsBinding.Set(cfg.HTTPVirtualHosts)
+
+		wsEnabledBinding.Set(cfg.WSEnabled)
+		wsRPCInterfaceBinding.Set(cfg.WSRPCInterface)
+		wsRPCHTTPPortBinding.Set(cfg.WSRPCHTTPPort)
+		wsRPCOriginsBinding.Set(cfg.WSRPCOrigins)
+		WSRPCAPIsCheck.SetSelected(cfg.WSRPCAPIs)
+		WSRPCAPIsBinding.Set(cfg.WSRPCAPIs)
+
+		graphQLEnabledBinding.Set(cfg.GraphQLEnabled)
+		graphQLCorsBinding.Set(cfg.GraphQLCors)
+		graphQLVirtualHostsBinding.Set(cfg.GraphQLVirtualHosts)
+
+		adminPortBinding.Set(cfg.AdminPort)
+		adminAddrBinding.Set(cfg.AdminAddr)
+
+		preloadJSBinding.Set(cfg.PreloadJS)
+		execJSBinding.Set(cfg.ExecJS)
+
+		dbEndpointBinding.Set(cfg.DBEndpoint)
+		txLookupLimitBinding.Set(cfg.TxLookupLimit)
+		syncModeBinding.Set(cfg.SyncMode)
+		networkIDBinding.Set(cfg.NetworkID)
+		p2pPortBinding.Set(cfg.P2PPort)
+		dataDirBinding.Set(cfg.DataDir)
+		ipcDisableBinding.Set(cfg.IPCDisable)
+		ipcPathBinding.Set(cfg.IPCPath)
+
+		userAddressBinding.Set(cfg.UserAddress)
+		minerThreadsBinding.Set(cfg.MinerThreads)
+		notifyURLsBinding.Set(cfg.NotifyURLs)
+		minerMinimumGasPriceBinding.Set(cfg.MinerMinimumGasPrice)
+		minerGasTargetBinding.Set(cfg.MinerGasTarget)
+		minerGasLimitBinding.Set(cfg.MinerGasLimit)
+		minerExtraDataBinding.Set(cfg.MinerExtraData)
+		minerRecommitBinding.Set(cfg.MinerRecommit)
+		minerNoverifyBinding.Set(cfg.MinerDisableRemoteSealing)
+
+		devModeBinding.Set(cfg.DeveloperMode)
+		devPeriodBinding.Set(cfg.DeveloperPeriod)
+		devGasLimitBinding.Set(cfg.DeveloperGasLimit)
+
+		extraArgsBinding.Set(gethargs.FormatArgLines(cfg.ExtraArgs))
+		envBinding.Set(cfg.Env)
+
+		if cfg.RestartPolicy == "" {
+			restartPolicySelect.ClearSelected()
+		} else {
+			restartPolicySelect.SetSelected(cfg.RestartPolicy)
+		}
+		restartPolicyBinding.Set(cfg.RestartPolicy)
+		maxRestartsBinding.Set(cfg.MaxRestarts)
+		restartWindowBinding.Set(cfg.RestartWindow)
+		readyTimeoutBinding.Set(cfg.ReadyTimeout)
+
+		bins.Set(cfg.Binary)
+	}
+
+	for field, e := range validatedEntries {
+		e.Validator = fieldValidator(readUserInput, field)
+	}
+	extraArgsEntry.Validator = extraArgsValidator(readUserInput)
+	validatedEntries["ExtraArgs"] = extraArgsEntry
+
+	// warnCompatibility lists the flags in cfg that the geth binary no longer
+	// supports, and those left out because it does not define them
+	warnCompatibility := func(cfg config.UserInputForNodeConfig) {
+		bin := currentBinary(bins.active)
+		cfg, dropped := dropUnsupported(currentHelp(bins.help), cfg)
+		warnings := gethbin.Check(gethargs.Build(cfg), bin.Version)
+		if len(dropped) > 0 {
+			warnings = append(warnings, "left out, geth does not define "+droppedFlags(dropped))
+		}
+		if len(warnings) == 0 {
+			return
+		}
+		fmt.Println(strings.Join(warnings, "\n"))
+		dialog.ShowInformation("geth "+bin.Version.String()+" compatibility", strings.Join(warnings, "\n"), myWindow)
+	}
+
+	manager := newNodeManager(nodes, nodeStore, profiles, bins, myWindow, cfg.StopTimeout, readUserInput, writeUserInput, nodeStateBinding, nodeExitBinding)
+
+	// reportStartError shows why geth could not be started, marking invalid
+	// fields inline and offering to move to free ports when one is taken
+	reportStartError := func(action string, err error) {
+		fmt.Printf("Error %s Geth: %s\n", action, err)
+		var busy ports.BusyError
+		if errors.As(err, &busy) {
+			manager.offerPortMove(formNodeName, busy)
+			return
+		}
+		var conflict *datadir.ConflictError
+		if errors.As(err, &conflict) {
+			dialog.ShowError(fmt.Errorf("error %s geth:\n%w", action, err), myWindow)
+			return
+		}
+		var errs config.ValidationErrors
+		if errors.As(err, &errs) {
+			markInvalidEntries(validatedEntries, errs)
+			dialog.ShowError(fmt.Errorf("fix the highlighted fields before %s geth:\n%w", action, err), myWindow)
+			return
+		}
+		nodeExitBinding.Set(fmt.Sprintf("Error %s Geth: %s", action, err))
+	}
+
+	controls := nodeControls{
+		node:        n,
+		window:      myWindow,
+		state:       nodeStateBinding,
+		status:      nodeExitBinding,
+		crash:       nodeCrashBinding,
+		crashOutput: nodeCrashOutputBinding,
+		start: func() {
+			cfg := readUserInput()
+			warnCompatibility(cfg)
+			if err := manager.start(formNodeName, cfg, false); err != nil {
+				reportStartError("starting", err)
+			}
+		},
+		stop: func() {
+			stopGeth(n)
+		},
+		restart: func() {
+			cfg := readUserInput()
+			warnCompatibility(cfg)
+			if err := manager.start(formNodeName, cfg, true); err != nil {
+				reportStartError("restarting", err)
+			}
+		},
+	}
 
 	BasicConfigTab := container.NewVBox(
+		bins.newPicker(),
 		userAddressInput,
 		minerThreadsInput,
 		notifyURLsInput,
 		tomlConfigInput,
-		startGethButton,
+		container.NewHBox(
+			newTOMLButtons(myWindow, readUserInput, writeUserInput),
+			newImportCommandLineButton(myWindow, readUserInput, writeUserInput),
+		),
+		controls.newToolbar(),
 	)
 
 	advancedConfigTab := container.NewVBox(
 		APIsToggleInput,
+		httpEnabledInput,
+		httpAddrInput,
 		rpcPortInput,
 		httpAPIMethodsInput,
+		httpCorsDomainInput,
+		httpVirtualHostsInput,
+		wsEnabledInput,
 		wsRPCInterfaceInput,
 		wsRPCHTTPPortInput,
 		wsRPCOriginsInput,
@@ -577,7 +916,14 @@ func main() {
 		networkIDInput,
 		p2pPortInput,
 		dataDirInput,
-		startGethButton,
+		ipcDisableInput,
+		ipcPathInput,
+		extraArgsInput,
+		restartPolicyInput,
+		maxRestartsInput,
+		restartWindowInput,
+		readyTimeoutInput,
+		controls.newToolbar(),
 	)
 
 	minerTab := container.NewVBox(
@@ -586,10 +932,12 @@ func main() {
 		notifyURLsInput,
 		minerMinimumGasPriceInput,
 		minerGasTargetInput,
+		minerGasLimitInput,
 		minerExtraDataInput,
 		minerRecommitInput,
 		minerNoverifyInput,
-		startGethButton,
+		newMiningControls(manager, myWindow, readUserInput),
+		controls.newToolbar(),
 	)
 
 	developerTab := container.NewVBox(
@@ -598,7 +946,8 @@ func main() {
 		devGasLimitInput,
 		preloadJSInput,
 		execJSInput,
-		startGethButton,
+		envInput,
+		controls.newToolbar(),
 	)
 
 	tab1Container := container.New(layout.NewAdaptiveGridLayout(1), BasicConfigTab)
@@ -607,173 +956,208 @@ func main() {
 	tab4Container := container.New(layout.NewAdaptiveGridLayout(1), developerTab)
 
 	tabs := container.NewAppTabs(
-		container.NewTabItem("Basic Config", tab1Container),
-		container.NewTabItem("Miner Config", tab3Container),
-		container.NewTabItem("Advanced Config", tab2Container),
-		container.NewTabItem("Developer Config", tab4Container),
+		container.NewTabItem(basicTabTitle, tab1Container),
+		container.NewTabItem(minerTabTitle, tab3Container),
+		container.NewTabItem(advancedTabTitle, tab2Container),
+		container.NewTabItem(developerTabTitle, tab4Container),
+		container.NewTabItem("Logs", newLogsTab(n.Logs())),
+		container.NewTabItem("Nodes", manager.newTab()),
+		container.NewTabItem("Status", newStatusTab(manager)),
+		container.NewTabItem("Peers", newPeersTab(manager, myWindow)),
 	)
 
-	final := container.New(layout.NewMaxLayout())
-	final.Add(tabs)
+	// tailor the form to the flags and API modules the geth binary supports
+	forms := flagForms{}
+	for field, form := range map[string]*widget.Form{
+		"TOMLConfig":                tomlConfigInput,
+		"HTTPEnabled":               httpEnabledInput,
+		"HTTPAddr":                  httpAddrInput,
+		"RPCHTTPPort":               rpcPortInput,
+		"RPCHTTPSelectedAPIMethods": httpAPIMethodsInput,
+		"HTTPCorsDomain":            httpCorsDomainInput,
+		"HTTPVirtualHosts":          httpVirtualHostsInput,
+		"WSEnabled":                 wsEnabledInput,
+		"WSRPCInterface":            wsRPCInterfaceInput,
+		"WSRPCHTTPPort":             wsRPCHTTPPortInput,
+		"WSRPCOrigins":              wsRPCOriginsInput,
+		"WSRPCAPIs":                 WSRPCAPIsInput,
+		"GraphQLEnabled":            graphQLEnabledInput,
+		"GraphQLCors":               graphQLCorsInput,
+		"GraphQLVirtualHosts":       graphQLVirtualHostsInput,
+		"AdminAddr":                 adminAddrInput,
+		"AdminPort":                 adminPortInput,
+		"PreloadJS":                 preloadJSInput,
+		"ExecJS":                    execJSInput,
+		"DBEndpoint":                dbEndpointInput,
+		"TxLookupLimit":             txLookupLimitInput,
+		"SyncMode":                  syncModeInput,
+		"NetworkID":                 networkIDInput,
+		"P2PPort":                   p2pPortInput,
+		"DataDir":                   dataDirInput,
+		"IPCDisable":                ipcDisableInput,
+		"IPCPath":                   ipcPathInput,
+		"UserAddress":               userAddressInput,
+		"MinerThreads":              minerThreadsInput,
+		"NotifyURLs":                notifyURLsInput,
+		"MinerMinimumGasPrice":      minerMinimumGasPriceInput,
+		"MinerGasTarget":            minerGasTargetInput,
+		"MinerGasLimit":             minerGasLimitInput,
+		"MinerExtraData":            minerExtraDataInput,
+		"MinerRecommit":             minerRecommitInput,
+		"MinerDisableRemoteSealing": minerNoverifyInput,
+		"DeveloperMode":             devModeInput,
+		"DeveloperPeriod":           devPeriodInput,
+		"DeveloperGasLimit":         devGasLimitInput,
+	} {
+		forms.add(field, form)
+	}
+	// the API modules come from the --http.api help of the binary, or its
+	// version until the help is loaded
+	updateAPIs := binding.NewDataListener(func() {
+		apis := gethbin.AvailableAPIs(currentHelp(bins.help), currentBinary(bins.active).Version)
+		httpAPIMethodsCheck.Options = apis
+		httpAPIMethodsCheck.Refresh()
+		WSRPCAPIsCheck.Options = apis
+		WSRPCAPIsCheck.Refresh()
+	})
+	bins.help.AddListener(binding.NewDataListener(func() {
+		forms.apply(currentHelp(bins.help))
+	}))
+	bins.help.AddListener(updateAPIs)
+	bins.active.AddListener(updateAPIs)
+
+	profileBar := newProfileBar(profiles, myWindow, readUserInput, writeUserInput)
+
+	// every binding that feeds readUserInput, so the preview can follow them
+	formBindings := []binding.DataItem{
+		tomlConfigBinding,
+		httpEnabledBinding, httpAddrBinding, rpcHTTPPortBinding, RPCHTTPSelectedAPIMethods, httpCorsDomainBinding, httpVirtualHostsBinding,
+		wsEnabledBinding, wsRPCInterfaceBinding, wsRPCHTTPPortBinding, wsRPCOriginsBinding, WSRPCAPIsBinding,
+		graphQLEnabledBinding, graphQLCorsBinding, graphQLVirtualHostsBinding,
+		adminPortBinding, adminAddrBinding,
+		preloadJSBinding, execJSBinding,
+		dbEndpointBinding, txLookupLimitBinding, syncModeBinding, networkIDBinding, p2pPortBinding, dataDirBinding, ipcDisableBinding, ipcPathBinding,
+		userAddressBinding, minerThreadsBinding, notifyURLsBinding, minerMinimumGasPriceBinding, minerGasTargetBinding, minerGasLimitBinding, minerExtraDataBinding, minerRecommitBinding, minerNoverifyBinding,
+		devModeBinding, devPeriodBinding, devGasLimitBinding,
+		extraArgsBinding, envBinding,
+		restartPolicyBinding, maxRestartsBinding, restartWindowBinding, readyTimeoutBinding,
+		bins.label,
+	}
+	preview := widget.NewAccordion(widget.NewAccordionItem("Command line preview",
+		newCommandPreview(myWindow, bins.active, bins.help, readUserInput, formBindings)))
+
+	final := container.NewBorder(profileBar, preview, nil, nil, tabs)
 
 	myWindow.SetContent(final)
 	myWindow.ShowAndRun()
-	n.tidyUp()
+	tidyUp(nodes)
 }
 
-func (n *Node) tidyUp() {
+// tidyUp stops every node GeNe started, leaving any other geth instances
+// on the machine untouched.
+func tidyUp(nodes *node.Registry) {
 	fmt.Println("Exited")
-	n.stopGeth()
+	for name, err := range nodes.StopAll() {
+		fmt.Printf("Error stopping %s: %s\n", name, err)
+	}
 }
 
-func (n *Node) stopGeth() {
-	// kill the geth process
-	cmd := exec.Command("killall", "geth")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Start()
+// stopGeth stops the geth process started by GeNe, leaving any other geth
+// instances on the machine untouched.
+func stopGeth(n *node.Node) {
+	s, err := n.Stop()
+	if err == node.ErrNotRunning {
+		return
+	}
 	if err != nil {
-		log.Fatal(err)
+		fmt.Println("Error stopping Geth:", err)
+		return
 	}
-
+	fmt.Println("Stopped Geth:", s)
 }
 
-func (n *Node) startGeth(UserInputForNodeConfig UserInputForNodeConfig) {
+func startGeth(n *node.Node, bins *binaries, UserInputForNodeConfig config.UserInputForNodeConfig) error {
 	fmt.Println("configuring Geth with user parameters...")
 	fmt.Printf("User Address: %s Data Directory: %s P2P Port: %s RPC Port: %s", UserInputForNodeConfig.UserAddress, UserInputForNodeConfig.DataDir, UserInputForNodeConfig.P2PPort, UserInputForNodeConfig.RPCHTTPPort)
 
-	// create a base command
-	cmd := exec.Command(n.GethFileLocation)
-
-	// check each paramenter in the UserInputForNodeConfig struct for a value, if it has a value then add it to the command
-	if UserInputForNodeConfig.TOMLConfig != "" {
-		cmd.Args = append(cmd.Args, "--config", UserInputForNodeConfig.TOMLConfig)
-	}
-
-	if UserInputForNodeConfig.RPCHTTPPort != "" {
-		cmd.Args = append(cmd.Args, "--http.port", UserInputForNodeConfig.RPCHTTPPort)
-	}
-
-	if len(UserInputForNodeConfig.RPCHTTPSelectedAPIMethods) > 0 {
-		cmd.Args = append(cmd.Args, "--http.api", strings.Join(UserInputForNodeConfig.RPCHTTPSelectedAPIMethods, ","))
-	}
-
-	if UserInputForNodeConfig.WSRPCInterface != "" {
-		cmd.Args = append(cmd.Args, "--ws.addr", UserInputForNodeConfig.WSRPCInterface)
-	}
-
-	if UserInputForNodeConfig.WSRPCHTTPPort != "" {
-		cmd.Args = append(cmd.Args, "--ws.port", UserInputForNodeConfig.WSRPCHTTPPort)
-	}
-
-	if UserInputForNodeConfig.WSRPCOrigins != "" {
-		cmd.Args = append(cmd.Args, "--ws.origins", UserInputForNodeConfig.WSRPCOrigins)
-	}
-
-	if len(UserInputForNodeConfig.WSRPCAPIs) > 0 {
-		cmd.Args = append(cmd.Args, "--ws.api", strings.Join(UserInputForNodeConfig.WSRPCAPIs, ","))
-	}
-
-	if UserInputForNodeConfig.GraphQLEnabled {
-		cmd.Args = append(cmd.Args, "--graphql")
-	}
-
-	if UserInputForNodeConfig.GraphQLCors != "" {
-		cmd.Args = append(cmd.Args, "--graphql.corsdomain", UserInputForNodeConfig.GraphQLCors)
-	}
-
-	if UserInputForNodeConfig.GraphQLVirtualHosts != "" {
-		cmd.Args = append(cmd.Args, "--graphql.vhosts", UserInputForNodeConfig.GraphQLVirtualHosts)
-	}
-
-	if UserInputForNodeConfig.AdminAddr != "" {
-		cmd.Args = append(cmd.Args, "--authrpc.addr", UserInputForNodeConfig.AdminAddr)
-	}
-
-	if UserInputForNodeConfig.AdminPort != "" {
-		cmd.Args = append(cmd.Args, "--authrpc.port", UserInputForNodeConfig.AdminPort)
-	}
-
-	if UserInputForNodeConfig.PreloadJS != "" {
-		cmd.Args = append(cmd.Args, "--preload", UserInputForNodeConfig.PreloadJS)
-	}
-
-	if UserInputForNodeConfig.ExecJS != "" {
-		cmd.Args = append(cmd.Args, "--exec", UserInputForNodeConfig.ExecJS)
-	}
-
-	if UserInputForNodeConfig.DBEndpoint != "" {
-		cmd.Args = append(cmd.Args, "--db.endpoint", UserInputForNodeConfig.DBEndpoint)
-	}
-
-	if UserInputForNodeConfig.TxLookupLimit != "" {
-		cmd.Args = append(cmd.Args, "--txlookuplimit", UserInputForNodeConfig.TxLookupLimit)
-	}
-
-	if UserInputForNodeConfig.SyncMode != "" {
-		cmd.Args = append(cmd.Args, "--syncmode", UserInputForNodeConfig.SyncMode)
-	}
-
-	if UserInputForNodeConfig.NetworkID != "" {
-		cmd.Args = append(cmd.Args, "--networkid", UserInputForNodeConfig.NetworkID)
-	}
-
-	if UserInputForNodeConfig.P2PPort != "" {
-		cmd.Args = append(cmd.Args, "--port", UserInputForNodeConfig.P2PPort)
-	}
-
-	if UserInputForNodeConfig.DataDir != "" {
-		cmd.Args = append(cmd.Args, "--datadir", UserInputForNodeConfig.DataDir)
-	}
-
-	if UserInputForNodeConfig.UserAddress != "" {
-		cmd.Args = append(cmd.Args, "--miner.etherbase", UserInputForNodeConfig.UserAddress)
-	}
-
-	if UserInputForNodeConfig.NotifyURLs != "" {
-		cmd.Args = append(cmd.Args, "--miner.notify", UserInputForNodeConfig.NotifyURLs)
+	fmt.Println("Starting Geth")
+	spec, err := nodeSpec(bins, UserInputForNodeConfig)
+	if err != nil {
+		return err
 	}
+	return n.Start(spec)
+}
 
-	if UserInputForNodeConfig.MinerMinimumGasPrice != "" {
-		cmd.Args = append(cmd.Args, "--miner.gasprice", UserInputForNodeConfig.MinerMinimumGasPrice)
+// restartGeth stops the running geth, if any, and starts it again with the current user parameters.
+func restartGeth(n *node.Node, bins *binaries, UserInputForNodeConfig config.UserInputForNodeConfig) error {
+	fmt.Println("Restarting Geth")
+	spec, err := nodeSpec(bins, UserInputForNodeConfig)
+	if err != nil {
+		return err
 	}
+	return n.Restart(spec)
+}
 
-	if UserInputForNodeConfig.MinerGasTarget != "" {
-		cmd.Args = append(cmd.Args, "--miner.gastarget", UserInputForNodeConfig.MinerGasTarget)
+// nodeSpec converts the user parameters into a launch spec for the node.
+// Settings whose flag the binary does not define are left out, as the form
+// hides them.
+func nodeSpec(bins *binaries, UserInputForNodeConfig config.UserInputForNodeConfig) (node.Spec, error) {
+	binary, err := bins.Path(UserInputForNodeConfig.Binary)
+	if err != nil {
+		return node.Spec{}, err
 	}
-
-	if UserInputForNodeConfig.MinerExtraData != "" {
-		cmd.Args = append(cmd.Args, "--miner.extradata", UserInputForNodeConfig.MinerExtraData)
+	if h, err := bins.helpCache.Load(binary); err != nil {
+		fmt.Println("Error reading geth flags:", err)
+	} else {
+		var dropped []gethargs.Mapping
+		UserInputForNodeConfig, dropped = dropUnsupported(h, UserInputForNodeConfig)
+		if len(dropped) > 0 {
+			fmt.Println("Leaving out flags geth does not define:", droppedFlags(dropped))
+		}
 	}
 
-	if UserInputForNodeConfig.MinerRecommit != "" {
-		cmd.Args = append(cmd.Args, "--miner.recommit", UserInputForNodeConfig.MinerRecommit)
+	errs := config.Validate(UserInputForNodeConfig)
+	errs = append(errs, gethargs.ValidateExtraArgs(UserInputForNodeConfig)...)
+	if len(errs) > 0 {
+		return node.Spec{}, errs
 	}
 
-	if UserInputForNodeConfig.MinerDisableRemoteSealing {
-		cmd.Args = append(cmd.Args, "--miner.noverify")
+	policy, err := node.ParseRestartPolicy(UserInputForNodeConfig.RestartPolicy)
+	if err != nil {
+		return node.Spec{}, err
 	}
+	restart := node.RestartConfig{Policy: policy}
 
-	if UserInputForNodeConfig.DeveloperMode {
-		cmd.Args = append(cmd.Args, "--dev")
+	if UserInputForNodeConfig.MaxRestarts != "" {
+		restart.MaxRestarts, err = strconv.Atoi(UserInputForNodeConfig.MaxRestarts)
+		if err != nil {
+			return node.Spec{}, fmt.Errorf("invalid max restarts %q: %w", UserInputForNodeConfig.MaxRestarts, err)
+		}
 	}
 
-	if UserInputForNodeConfig.DeveloperPeriod != "" {
-		cmd.Args = append(cmd.Args, "--dev.period", UserInputForNodeConfig.DeveloperPeriod)
+	if UserInputForNodeConfig.RestartWindow != "" {
+		restart.Window, err = time.ParseDuration(UserInputForNodeConfig.RestartWindow)
+		if err != nil {
+			return node.Spec{}, fmt.Errorf("invalid restart window %q: %w", UserInputForNodeConfig.RestartWindow, err)
+		}
 	}
 
-	if UserInputForNodeConfig.DeveloperGasLimit != "" {
-		cmd.Args = append(cmd.Args, "--dev.gaslimit", UserInputForNodeConfig.DeveloperGasLimit)
+	ready := node.ReadyConfig{
+		IPCPath: config.IPCEndpoint(UserInputForNodeConfig),
+		HTTPURL: config.HTTPEndpoint(UserInputForNodeConfig),
 	}
-
-	fmt.Println("Starting Geth")
-
-	// execute the comand line
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Start()
-	if err != nil {
-		log.Fatal(err)
+	if UserInputForNodeConfig.ReadyTimeout != "" {
+		ready.Timeout, err = time.ParseDuration(UserInputForNodeConfig.ReadyTimeout)
+		if err != nil {
+			return node.Spec{}, fmt.Errorf("invalid ready timeout %q: %w", UserInputForNodeConfig.ReadyTimeout, err)
+		}
 	}
 
+	return node.Spec{
+		Binary:  binary,
+		Args:    gethargs.Build(UserInputForNodeConfig),
+		Env:     config.Environ(UserInputForNodeConfig.Env),
+		Restart: restart,
+		Ready:   ready,
+	}, nil
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/mining"
+	"gene/internal/node"
+	"gene/internal/rpc"
+)
+
+// miningPollInterval is how often the Miner tab reads the mining state.
+const miningPollInterval = 3 * time.Second
+
+// miningControls starts and stops mining on the form node while it runs,
+// and applies the miner settings edited in the Miner tab to it live when
+// asked to. Loading a profile or importing a configuration only changes
+// the form, never the running node.
+type miningControls struct {
+	manager  *nodeManager
+	window   fyne.Window
+	readForm func() config.UserInputForNodeConfig
+	follower *nodeFollower
+
+	status  binding.String
+	applied binding.String
+}
+
+// newMiningControls builds the mining controls of the Miner tab.
+func newMiningControls(manager *nodeManager, window fyne.Window, readForm func() config.UserInputForNodeConfig) fyne.CanvasObject {
+	c := &miningControls{
+		manager:  manager,
+		window:   window,
+		readForm: readForm,
+		status:   binding.NewString(),
+		applied:  binding.NewString(),
+	}
+	// the follower stays on the form node, whose settings the Miner tab edits
+	c.follower = newNodeFollower(manager, miningPollInterval, c.poll, func(reason string) {
+		c.status.Set(reason)
+	}, func() {})
+
+	startButton := widget.NewButton("Start mining", func() {
+		c.run("starting mining", func(ctx context.Context, client *rpc.Client) error {
+			return mining.Start(ctx, client, c.readForm())
+		})
+	})
+	stopButton := widget.NewButton("Stop mining", func() {
+		c.run("stopping mining", func(ctx context.Context, client *rpc.Client) error {
+			return client.MinerStop(ctx)
+		})
+	})
+	applyButton := widget.NewButton("Apply to running node", func() {
+		go c.apply()
+	})
+
+	status := widget.NewLabelWithData(c.status)
+	status.Wrapping = fyne.TextWrapWord
+	applied := widget.NewLabelWithData(c.applied)
+	applied.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(
+		widget.NewForm(widget.NewFormItem("Mining on the running node", container.NewHBox(startButton, stopButton, applyButton))),
+		status,
+		applied,
+	)
+}
+
+// poll reads whether the node mines and at which hashrate.
+func (c *miningControls) poll(ctx context.Context, client *rpc.Client, _ config.UserInputForNodeConfig) error {
+	mining, err := client.Mining(ctx)
+	if err != nil {
+		return err
+	}
+	if !mining {
+		c.status.Set("Not mining")
+		return nil
+	}
+	// eth_hashrate is gone from releases without ethash
+	if hashrate, err := client.Hashrate(ctx); err == nil {
+		c.status.Set(fmt.Sprintf("Mining at %d H/s", hashrate))
+	} else {
+		c.status.Set("Mining")
+	}
+	return nil
+}
+
+// run calls fn on the running form node in the background and reports failures.
+func (c *miningControls) run(action string, fn func(ctx context.Context, client *rpc.Client) error) {
+	go func() {
+		if err := c.follower.do(fn); err != nil {
+			dialog.ShowError(fmt.Errorf("error %s:\n%w", action, err), c.window)
+		}
+	}()
+}
+
+// apply sends the miner settings, the fields in mining.Fields, that changed
+// since the form node started, or since they were last applied, to the node
+// while it is Ready.
+func (c *miningControls) apply() {
+	n, ok := c.manager.registry.Get(formNodeName)
+	if !ok || n.State() != node.Ready {
+		c.applied.Set("Miner settings not applied: the node is not running")
+		return
+	}
+	cfg := c.readForm()
+	var invalid []string
+	for _, e := range config.Validate(cfg) {
+		for _, f := range mining.Fields {
+			if e.Field == f {
+				invalid = append(invalid, e.Error())
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		c.applied.Set("Miner settings not applied:\n" + strings.Join(invalid, "\n"))
+		return
+	}
+
+	err := c.follower.do(func(ctx context.Context, client *rpc.Client) error {
+		running, _, err := c.manager.endpoint(formNodeName)
+		if err != nil {
+			return err
+		}
+		applied, err := mining.Apply(ctx, client, running, cfg)
+		if len(applied) > 0 {
+			c.manager.updateLaunched(formNodeName, func(running config.UserInputForNodeConfig) config.UserInputForNodeConfig {
+				return mining.Merge(running, cfg, applied)
+			})
+			c.applied.Set(fmt.Sprintf("Applied %s at %s without a restart", strings.Join(applied, ", "), time.Now().Format("15:04:05")))
+		} else if err == nil {
+			c.applied.Set("The running node already has these miner settings")
+		}
+		return err
+	})
+	if err != nil {
+		c.applied.Set(fmt.Sprintf("Error applying miner settings: %s", err))
+	}
+}
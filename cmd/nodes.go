@@ -0,0 +1,595 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/datadir"
+	"gene/internal/gethargs"
+	"gene/internal/node"
+	"gene/internal/ports"
+	"gene/internal/rpc"
+)
+
+// formNodeName is the name of the node edited in the config tabs.
+const formNodeName = "form"
+
+// errNoEndpoint is returned by dial for a node that offers no RPC endpoint.
+var errNoEndpoint = errors.New("geth offers no IPC, HTTP or WebSocket endpoint, enable one of them to reach it")
+
+// nodeManager runs the nodes listed in the Nodes tab. Every node but the
+// form node is started from its own profile and has its own process, logs
+// and status.
+type nodeManager struct {
+	registry    *node.Registry
+	store       *config.NodeStore
+	profiles    *config.ProfileStore
+	bins        *binaries
+	window      fyne.Window
+	stopTimeout time.Duration
+	// readForm and writeForm get and set the configuration of the form node
+	readForm  func() config.UserInputForNodeConfig
+	writeForm func(config.UserInputForNodeConfig)
+
+	mu      sync.Mutex
+	entries []config.NodeEntry
+	// launched is the configuration each node was last started with
+	launched map[string]config.UserInputForNodeConfig
+	// locks are the datadir locks held for the nodes that run
+	locks map[string]*datadir.Lock
+
+	rows       map[string]fyne.CanvasObject
+	list       *fyne.Container
+	collisions binding.String
+}
+
+// newNodeManager loads the stored nodes next to the form node, which must
+// already be in registry and reports its state and status through the
+// given bindings.
+func newNodeManager(registry *node.Registry, store *config.NodeStore, profiles *config.ProfileStore, bins *binaries, window fyne.Window, stopTimeout time.Duration, readForm func() config.UserInputForNodeConfig, writeForm func(config.UserInputForNodeConfig), formState, formStatus binding.String) *nodeManager {
+	m := &nodeManager{
+		registry:    registry,
+		store:       store,
+		profiles:    profiles,
+		bins:        bins,
+		window:      window,
+		stopTimeout: stopTimeout,
+		readForm:    readForm,
+		writeForm:   writeForm,
+		launched:    make(map[string]config.UserInputForNodeConfig),
+		locks:       make(map[string]*datadir.Lock),
+		rows:        make(map[string]fyne.CanvasObject),
+		list:        container.NewVBox(),
+		collisions:  binding.NewString(),
+	}
+
+	m.track(formNodeName, "", formState, formStatus)
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Println("Error loading nodes:", err)
+	}
+	for _, e := range entries {
+		if err := m.add(e); err != nil {
+			fmt.Println("Error adding node:", err)
+		}
+	}
+	return m
+}
+
+// add creates the node for e and wires its status into a row of the tab.
+func (m *nodeManager) add(e config.NodeEntry) error {
+	n := &node.Node{
+		GethFileLocation: currentBinary(m.bins.detected).Path,
+		StopTimeout:      m.stopTimeout,
+	}
+	if err := m.registry.Add(e.Name, n); err != nil {
+		return err
+	}
+
+	state := binding.NewString()
+	status := binding.NewString()
+	n.OnExit = func(s node.ExitStatus) {
+		fmt.Printf("%s: %s\n", e.Name, s)
+		status.Set(s.String())
+	}
+	n.OnCrash = func(r node.CrashReport) {
+		fmt.Printf("%s: %s\n", e.Name, r)
+		status.Set(r.String())
+	}
+	n.OnNotReady = func(err *node.ReadyError) {
+		fmt.Printf("%s: %s\n", e.Name, err)
+		status.Set(err.Error())
+	}
+	state.Set(n.State().String())
+	n.AddStateListener(func(s node.State) {
+		state.Set(s.String())
+	})
+
+	m.mu.Lock()
+	m.entries = append(m.entries, e)
+	m.mu.Unlock()
+	m.track(e.Name, e.Profile, state, status)
+	return nil
+}
+
+// track adds the row for the node called name, reporting state and status.
+func (m *nodeManager) track(name, profile string, state, status binding.String) {
+	n, _ := m.registry.Get(name)
+
+	title := widget.NewLabel(name)
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	source := "profile " + profile
+	if name == formNodeName {
+		source = "config tabs"
+	}
+
+	startButton := widget.NewButton("Start", func() {
+		go func() {
+			if err := m.startNamed(name, false); err != nil {
+				m.reportError(name, err)
+			}
+		}()
+	})
+	stopButton := widget.NewButton("Stop", func() {
+		go func() {
+			if _, err := n.Stop(); err != nil && err != node.ErrNotRunning {
+				status.Set(fmt.Sprintf("Error stopping geth: %s", err))
+			}
+		}()
+	})
+	logsButton := widget.NewButton("Logs", func() {
+		w := fyne.CurrentApp().NewWindow(name + " logs")
+		w.SetContent(newLogsTab(n.Logs()))
+		w.Resize(fyne.NewSize(1000, 700))
+		w.Show()
+	})
+	attachButton := widget.NewButton("Attach", func() { m.attach(name) })
+	removeButton := widget.NewButton("Remove", func() { m.remove(name) })
+	if name == formNodeName {
+		removeButton.Hide()
+	}
+
+	state.AddListener(binding.NewDataListener(func() {
+		s := n.State()
+		setEnabled(startButton, !s.Active())
+		// Stop also cancels the restart scheduled for a crashed node
+		setEnabled(stopButton, s == node.Starting || s == node.Ready || n.RestartPending())
+		setEnabled(attachButton, s == node.Ready)
+		setEnabled(removeButton, !s.Active())
+		if s == node.Stopped {
+			m.unlockDataDir(name)
+		}
+		m.checkCollisions()
+	}))
+
+	statusLabel := widget.NewLabelWithData(status)
+	statusLabel.Wrapping = fyne.TextWrapWord
+	row := container.NewVBox(
+		container.NewHBox(title, widget.NewLabel(source), widget.NewLabelWithData(state),
+			startButton, stopButton, logsButton, attachButton, removeButton),
+		statusLabel,
+		widget.NewSeparator(),
+	)
+
+	m.rows[name] = row
+	m.list.Add(row)
+}
+
+// config returns the configuration of the node called name: the form for
+// the form node, its profile for the others.
+func (m *nodeManager) config(name string) (config.UserInputForNodeConfig, error) {
+	if name == formNodeName {
+		return m.readForm(), nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.Name == name {
+			return m.profiles.Load(e.Profile)
+		}
+	}
+	return config.UserInputForNodeConfig{}, fmt.Errorf("%q: %w", name, node.ErrUnknownNode)
+}
+
+// configs returns the configuration of every node: the one it was started
+// with while it runs, its current one otherwise.
+func (m *nodeManager) configs() map[string]config.UserInputForNodeConfig {
+	cfgs := make(map[string]config.UserInputForNodeConfig)
+	for _, name := range m.registry.Names() {
+		n, _ := m.registry.Get(name)
+		m.mu.Lock()
+		cfg, ok := m.launched[name]
+		m.mu.Unlock()
+		if !ok || !n.State().Active() {
+			var err error
+			if cfg, err = m.config(name); err != nil {
+				continue
+			}
+		}
+		cfgs[name] = cfg
+	}
+	return cfgs
+}
+
+// collisionsWithRunning returns the collisions between cfg, for the node
+// called name, and the nodes that are running.
+func (m *nodeManager) collisionsWithRunning(name string, cfg config.UserInputForNodeConfig) []config.Collision {
+	running := map[string]config.UserInputForNodeConfig{name: cfg}
+	m.mu.Lock()
+	for other, c := range m.launched {
+		if n, ok := m.registry.Get(other); ok && other != name && n.State().Active() {
+			running[other] = c
+		}
+	}
+	m.mu.Unlock()
+
+	var collisions []config.Collision
+	for _, c := range config.Collisions(running) {
+		if c.Involves(name) {
+			collisions = append(collisions, c)
+		}
+	}
+	return collisions
+}
+
+// start launches, or relaunches, the node called name with cfg unless it
+// would share a datadir or port with a running node, its datadir is used by
+// another geth or GeNe instance, or one of its ports is already taken, in
+// which case a ports.BusyError is returned.
+func (m *nodeManager) start(name string, cfg config.UserInputForNodeConfig, restart bool) (err error) {
+	n, ok := m.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("%q: %w", name, node.ErrUnknownNode)
+	}
+	if collisions := m.collisionsWithRunning(name, cfg); len(collisions) > 0 {
+		msgs := make([]string, len(collisions))
+		for i, c := range collisions {
+			msgs[i] = c.String()
+		}
+		return fmt.Errorf("%s would collide with a running node:\n%s", name, strings.Join(msgs, "\n"))
+	}
+
+	// a running node holds its own datadir and ports until Restart stops it
+	active := n.State().Active()
+	if err := m.lockDataDir(name, cfg, active); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && !n.State().Active() {
+			m.unlockDataDir(name)
+		}
+	}()
+	if !active {
+		if err := ports.Check(cfg); err != nil {
+			return err
+		}
+	}
+
+	if restart {
+		err = restartGeth(n, m.bins, cfg)
+	} else {
+		err = startGeth(n, m.bins, cfg)
+	}
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.launched[name] = cfg
+	m.mu.Unlock()
+	m.checkCollisions()
+	return nil
+}
+
+// lockDataDir takes the GeNe lock on the datadir of the node called name,
+// so no other GeNe instance can drive it, and unless the node already runs
+// checks that no geth uses it.
+func (m *nodeManager) lockDataDir(name string, cfg config.UserInputForNodeConfig, active bool) error {
+	dir := config.DataDir(cfg)
+	m.mu.Lock()
+	held := m.locks[name]
+	m.mu.Unlock()
+
+	if held == nil || held.Dir() != dir {
+		m.unlockDataDir(name)
+		if dir == "" {
+			// developer mode without a datadir, geth uses a temporary directory
+			return nil
+		}
+		l, err := datadir.Acquire(dir)
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		m.locks[name] = l
+		m.mu.Unlock()
+	}
+	if active {
+		return nil
+	}
+	return datadir.Check(dir)
+}
+
+// unlockDataDir releases the datadir lock held for the node called name, if any.
+func (m *nodeManager) unlockDataDir(name string) {
+	m.mu.Lock()
+	l := m.locks[name]
+	delete(m.locks, name)
+	m.mu.Unlock()
+	if l != nil {
+		l.Release()
+	}
+}
+
+// startNamed starts the node called name with its current configuration.
+func (m *nodeManager) startNamed(name string, restart bool) error {
+	cfg, err := m.config(name)
+	if err != nil {
+		return err
+	}
+	return m.start(name, cfg, restart)
+}
+
+func (m *nodeManager) reportError(name string, err error) {
+	fmt.Printf("Error starting %s: %s\n", name, err)
+	var busy ports.BusyError
+	if errors.As(err, &busy) {
+		m.offerPortMove(name, busy)
+		return
+	}
+	var errs config.ValidationErrors
+	if errors.As(err, &errs) {
+		err = fmt.Errorf("fix the profile of %s before starting it:\n%w", name, err)
+	}
+	dialog.ShowError(err, m.window)
+}
+
+// offerPortMove reports the busy ports of the node called name and offers
+// to move it to the next block of ports that are free and not used by
+// another node, saving the new ports and starting it.
+func (m *nodeManager) offerPortMove(name string, busy ports.BusyError) {
+	cfg, err := m.config(name)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	reserved := make(map[int]bool)
+	for other, c := range m.configs() {
+		if other == name {
+			continue
+		}
+		for _, p := range config.ListenPorts(c) {
+			reserved[p.Port] = true
+		}
+	}
+
+	moved, err := ports.MoveToFreeBlock(cfg, reserved)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("%w\n\n%s", busy, err), m.window)
+		return
+	}
+	msg := fmt.Sprintf("%s\n\nMove %s to HTTP %s, WS %s, authrpc %s and P2P %s and start it?",
+		busy, name, moved.RPCHTTPPort, moved.WSRPCHTTPPort, moved.AdminPort, moved.P2PPort)
+	dialog.ShowConfirm("Ports in use", msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := m.save(name, moved); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if err := m.start(name, moved, false); err != nil {
+			m.reportError(name, err)
+		}
+	}, m.window)
+}
+
+// save stores cfg as the configuration of the node called name: in the
+// form for the form node, in its profile for the others.
+func (m *nodeManager) save(name string, cfg config.UserInputForNodeConfig) error {
+	if name == formNodeName {
+		m.writeForm(cfg)
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.Name == name {
+			return m.profiles.Save(e.Profile, cfg)
+		}
+	}
+	return fmt.Errorf("%q: %w", name, node.ErrUnknownNode)
+}
+
+// checkCollisions refreshes the overview of datadirs and ports shared by nodes.
+func (m *nodeManager) checkCollisions() {
+	collisions := config.Collisions(m.configs())
+	if len(collisions) == 0 {
+		m.collisions.Set("No datadir or port is shared between nodes.")
+		return
+	}
+	msgs := make([]string, len(collisions))
+	for i, c := range collisions {
+		msgs[i] = c.String()
+	}
+	m.collisions.Set("Shared between nodes, only one of them can run at a time:\n" + strings.Join(msgs, "\n"))
+}
+
+// remove unregisters the node called name once it has stopped or crashed.
+func (m *nodeManager) remove(name string) {
+	if err := m.registry.Remove(name); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	// a crashed node keeps its datadir locked for the restart Remove cancelled
+	m.unlockDataDir(name)
+
+	m.mu.Lock()
+	for i, e := range m.entries {
+		if e.Name == name {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			break
+		}
+	}
+	delete(m.launched, name)
+	entries := append([]config.NodeEntry(nil), m.entries...)
+	m.mu.Unlock()
+
+	if err := m.store.Save(entries); err != nil {
+		dialog.ShowError(err, m.window)
+	}
+	m.list.Remove(m.rows[name])
+	delete(m.rows, name)
+	m.checkCollisions()
+}
+
+// askNode asks for the name and profile of a new node and adds it.
+func (m *nodeManager) askNode() {
+	profiles, err := m.profiles.List()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	if len(profiles) == 0 {
+		dialog.ShowInformation("No profiles", "Save the configuration as a profile first, every node is started from one.", m.window)
+		return
+	}
+
+	name := widget.NewEntry()
+	name.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("name is empty")
+		}
+		if _, ok := m.registry.Get(s); ok {
+			return node.ErrDuplicateNode
+		}
+		return nil
+	}
+	profile := widget.NewSelect(profiles, nil)
+	profile.SetSelectedIndex(0)
+
+	dialog.ShowForm("Add node", "Add", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Profile", profile),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := m.add(config.NodeEntry{Name: name.Text, Profile: profile.Selected}); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		m.mu.Lock()
+		entries := append([]config.NodeEntry(nil), m.entries...)
+		m.mu.Unlock()
+		if err := m.store.Save(entries); err != nil {
+			dialog.ShowError(err, m.window)
+		}
+		m.checkCollisions()
+	}, m.window)
+}
+
+// newTab builds the Nodes overview tab.
+func (m *nodeManager) newTab() fyne.CanvasObject {
+	collisions := widget.NewLabelWithData(m.collisions)
+	collisions.Wrapping = fyne.TextWrapWord
+	m.checkCollisions()
+
+	return container.NewBorder(
+		container.NewHBox(
+			widget.NewButton("Add node", m.askNode),
+			widget.NewButton("Check collisions", m.checkCollisions),
+		),
+		collisions, nil, nil,
+		container.NewVScroll(m.list),
+	)
+}
+
+// endpoint returns the configuration the node called name runs with and
+// the endpoint GeNe reaches it on, see config.Endpoint.
+func (m *nodeManager) endpoint(name string) (config.UserInputForNodeConfig, string, error) {
+	n, ok := m.registry.Get(name)
+	if !ok {
+		return config.UserInputForNodeConfig{}, "", fmt.Errorf("%q: %w", name, node.ErrUnknownNode)
+	}
+	m.mu.Lock()
+	cfg, launched := m.launched[name]
+	m.mu.Unlock()
+	if !launched || !n.State().Active() {
+		return config.UserInputForNodeConfig{}, "", fmt.Errorf("%s: %w", name, node.ErrNotRunning)
+	}
+	endpoint := config.Endpoint(cfg)
+	if endpoint == "" {
+		return cfg, "", fmt.Errorf("%s: %w", name, errNoEndpoint)
+	}
+	return cfg, endpoint, nil
+}
+
+// dial connects to the running node called name, over IPC unless the node
+// disabled it.
+func (m *nodeManager) dial(name string) (*rpc.Client, error) {
+	_, endpoint, err := m.endpoint(name)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.Dial(endpoint)
+}
+
+// updateLaunched records settings changed on the running node called name
+// without restarting it, such as the miner settings.
+func (m *nodeManager) updateLaunched(name string, update func(config.UserInputForNodeConfig) config.UserInputForNodeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cfg, ok := m.launched[name]; ok {
+		m.launched[name] = update(cfg)
+	}
+}
+
+// attach checks that GeNe can reach the node called name and shows the
+// command that opens a geth console on the same endpoint.
+func (m *nodeManager) attach(name string) {
+	cfg, endpoint, err := m.endpoint(name)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	binary, err := m.bins.Path(cfg.Binary)
+	if err != nil {
+		binary = "geth"
+	}
+	command := gethargs.QuoteCommand([]string{binary, "attach", endpoint})
+
+	go func() {
+		client, err := m.dial(name)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error attaching to %s:\n%w", name, err), m.window)
+			return
+		}
+		defer client.Close()
+		version, err := client.ClientVersion(context.Background())
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error attaching to %s:\n%w", name, err), m.window)
+			return
+		}
+
+		info := widget.NewLabel(fmt.Sprintf("%s answers on %s\n\nOpen a console with:\n%s", version, endpoint, command))
+		info.Wrapping = fyne.TextWrapWord
+		copyButton := widget.NewButton("Copy command", func() {
+			m.window.Clipboard().SetContent(command)
+		})
+		d := dialog.NewCustom("Attach to "+name, "Close", container.NewVBox(info, copyButton), m.window)
+		d.Resize(fyne.NewSize(700, 250))
+		d.Show()
+	}()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/rpc"
+)
+
+// peersPollInterval is how often the Peers tab refreshes the peer list.
+const peersPollInterval = 3 * time.Second
+
+// peerList shows the peers of a running node in the Peers tab and wires
+// peers by hand through the admin API.
+type peerList struct {
+	window   fyne.Window
+	follower *nodeFollower
+	summary  binding.String
+
+	mu    sync.Mutex
+	peers []rpc.PeerInfo
+
+	list *widget.List
+}
+
+// newPeersTab builds the "Peers" tab for the node picked from the nodes
+// of the Nodes tab. The node must offer the admin API on the endpoint GeNe
+// reaches it on, which IPC always does.
+func newPeersTab(manager *nodeManager, window fyne.Window) fyne.CanvasObject {
+	p := &peerList{
+		window:  window,
+		summary: binding.NewString(),
+	}
+	p.list = widget.NewList(
+		func() int {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return len(p.peers)
+		},
+		func() fyne.CanvasObject {
+			// rows are as tall as the template, which has the three lines of describePeer
+			info := widget.NewLabel("\n\n")
+			info.TextStyle = fyne.TextStyle{Monospace: true}
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(widget.NewButton("Trust", nil), widget.NewButton("Remove", nil)), info)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			p.mu.Lock()
+			if id >= len(p.peers) {
+				p.mu.Unlock()
+				return
+			}
+			peer := p.peers[id]
+			p.mu.Unlock()
+
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(describePeer(peer))
+			buttons := row.Objects[1].(*fyne.Container)
+			trust := buttons.Objects[0].(*widget.Button)
+			if peer.Network.Trusted {
+				trust.SetText("Untrust")
+				trust.OnTapped = func() { p.act("removing trusted peer", peer.Enode, (*rpc.Client).RemoveTrustedPeer) }
+			} else {
+				trust.SetText("Trust")
+				trust.OnTapped = func() { p.act("adding trusted peer", peer.Enode, (*rpc.Client).AddTrustedPeer) }
+			}
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				p.act("removing peer", peer.Enode, (*rpc.Client).RemovePeer)
+			}
+		},
+	)
+	p.follower = newNodeFollower(manager, peersPollInterval, p.poll, func(reason string) {
+		p.show(nil)
+		p.summary.Set(reason)
+	}, func() { p.show(nil) })
+
+	enode := widget.NewEntry()
+	enode.SetPlaceHolder("enode://<node id>@host:port")
+	enode.Validator = config.ValidateEnode
+	withEnode := func(action string, call func(*rpc.Client, context.Context, string) (bool, error)) func() {
+		return func() {
+			if err := enode.Validate(); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			p.act(action, strings.TrimSpace(enode.Text), call)
+		}
+	}
+	add := container.NewBorder(nil, nil, nil, container.NewHBox(
+		widget.NewButton("Add peer", withEnode("adding peer", (*rpc.Client).AddPeer)),
+		widget.NewButton("Remove peer", withEnode("removing peer", (*rpc.Client).RemovePeer)),
+		widget.NewButton("Add trusted", withEnode("adding trusted peer", (*rpc.Client).AddTrustedPeer)),
+		widget.NewButton("Remove trusted", withEnode("removing trusted peer", (*rpc.Client).RemoveTrustedPeer)),
+	), enode)
+
+	summary := widget.NewLabelWithData(p.summary)
+	summary.Wrapping = fyne.TextWrapWord
+	top := container.NewVBox(
+		widget.NewForm(widget.NewFormItem("Node", p.follower.picker)),
+		widget.NewForm(widget.NewFormItem("Peer", add)),
+		summary,
+	)
+	return container.NewBorder(top, nil, nil, nil, p.list)
+}
+
+// poll reads the connected peers of the node.
+func (p *peerList) poll(ctx context.Context, client *rpc.Client, _ config.UserInputForNodeConfig) error {
+	peers, err := client.Peers(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	p.show(peers)
+
+	inbound, trusted := 0, 0
+	for _, peer := range peers {
+		if peer.Network.Inbound {
+			inbound++
+		}
+		if peer.Network.Trusted {
+			trusted++
+		}
+	}
+	p.summary.Set(fmt.Sprintf("%d peers connected, %d inbound, %d trusted", len(peers), inbound, trusted))
+	return nil
+}
+
+func (p *peerList) show(peers []rpc.PeerInfo) {
+	p.mu.Lock()
+	p.peers = peers
+	p.mu.Unlock()
+	p.list.Refresh()
+}
+
+// act calls an admin_ method with enode on the followed node in the
+// background and reports failures.
+func (p *peerList) act(action, enode string, call func(*rpc.Client, context.Context, string) (bool, error)) {
+	go func() {
+		err := p.follower.do(func(ctx context.Context, client *rpc.Client) error {
+			ok, err := call(client, ctx, enode)
+			if err == nil && !ok {
+				err = errors.New("geth refused the request")
+			}
+			return err
+		})
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error %s %s:\n%w", action, enode, err), p.window)
+		}
+	}()
+}
+
+// describePeer formats peer for the peer list.
+func describePeer(peer rpc.PeerInfo) string {
+	flags := []string{peer.Direction()}
+	if peer.Network.Trusted {
+		flags = append(flags, "trusted")
+	}
+	if peer.Network.Static {
+		flags = append(flags, "static")
+	}
+	head := peer.Head()
+	if head == "" {
+		head = "-"
+	}
+	return fmt.Sprintf("%s (%s) %s\n%s\nprotocols: %s  head: %s",
+		peer.Name, strings.Join(flags, ", "), peer.Network.RemoteAddress,
+		peer.Enode, strings.Join(peer.Caps, " "), head)
+}
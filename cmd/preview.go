@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/gethargs"
+	"gene/internal/gethbin"
+)
+
+// Tab titles, shared by the tab container and the command line preview.
+const (
+	basicTabTitle     = "Basic Config"
+	minerTabTitle     = "Miner Config"
+	advancedTabTitle  = "Advanced Config"
+	developerTabTitle = "Developer Config"
+)
+
+// fieldTabs records on which tabs each config.UserInputForNodeConfig field
+// can be edited, so the preview can tell where a flag came from.
+var fieldTabs = map[string][]string{
+	"TOMLConfig": {basicTabTitle},
+	"Binary":     {basicTabTitle},
+
+	"UserAddress":  {basicTabTitle, minerTabTitle},
+	"MinerThreads": {basicTabTitle, minerTabTitle},
+	"NotifyURLs":   {basicTabTitle, minerTabTitle},
+
+	"MinerMinimumGasPrice":      {minerTabTitle},
+	"MinerGasTarget":            {minerTabTitle},
+	"MinerGasLimit":             {minerTabTitle},
+	"MinerExtraData":            {minerTabTitle},
+	"MinerRecommit":             {minerTabTitle},
+	"MinerDisableRemoteSealing": {minerTabTitle},
+
+	"HTTPEnabled":               {advancedTabTitle},
+	"HTTPAddr":                  {advancedTabTitle},
+	"RPCHTTPPort":               {advancedTabTitle},
+	"RPCHTTPSelectedAPIMethods": {advancedTabTitle},
+	"HTTPCorsDomain":            {advancedTabTitle},
+	"HTTPVirtualHosts":          {advancedTabTitle},
+	"WSEnabled":                 {advancedTabTitle},
+	"WSRPCInterface":            {advancedTabTitle},
+	"WSRPCHTTPPort":             {advancedTabTitle},
+	"WSRPCOrigins":              {advancedTabTitle},
+	"WSRPCAPIs":                 {advancedTabTitle},
+	"GraphQLEnabled":            {advancedTabTitle},
+	"GraphQLCors":               {advancedTabTitle},
+	"GraphQLVirtualHosts":       {advancedTabTitle},
+	"AdminAddr":                 {advancedTabTitle},
+	"AdminPort":                 {advancedTabTitle},
+	"DBEndpoint":                {advancedTabTitle},
+	"TxLookupLimit":             {advancedTabTitle},
+	"SyncMode":                  {advancedTabTitle},
+	"NetworkID":                 {advancedTabTitle},
+	"P2PPort":                   {advancedTabTitle},
+	"DataDir":                   {advancedTabTitle},
+	"IPCDisable":                {advancedTabTitle},
+	"IPCPath":                   {advancedTabTitle},
+	"ExtraArgs":                 {advancedTabTitle},
+	"Env":                       {developerTabTitle},
+
+	"DeveloperMode":     {developerTabTitle},
+	"DeveloperPeriod":   {developerTabTitle},
+	"DeveloperGasLimit": {developerTabTitle},
+	"PreloadJS":         {developerTabTitle},
+	"ExecJS":            {developerTabTitle},
+}
+
+// newCommandPreview builds a panel showing the exact command line startGeth
+// would run, refreshed whenever one of the form bindings or the geth binary
+// changes. Flags the binary no longer supports are listed below it, as are
+// those left out because its help does not list them.
+func newCommandPreview(window fyne.Window, binary, help binding.Untyped, read func() config.UserInputForNodeConfig, bindings []binding.DataItem) fyne.CanvasObject {
+	command := widget.NewMultiLineEntry()
+	command.Wrapping = fyne.TextWrapWord
+	command.TextStyle = fyne.TextStyle{Monospace: true}
+	command.SetMinRowsVisible(3)
+
+	sources := widget.NewLabel("")
+	sources.TextStyle = fyne.TextStyle{Monospace: true}
+
+	warnings := widget.NewLabel("")
+	warnings.Wrapping = fyne.TextWrapWord
+	warnings.Hide()
+
+	var env []config.EnvVar
+	var argv []string
+	refresh := func() {
+		cfg, dropped := dropUnsupported(currentHelp(help), read())
+		args := gethargs.Annotate(cfg)
+		env = cfg.Env
+
+		bin := currentBinary(binary)
+		argv = []string{bin.Path}
+		var lines []string
+		for _, a := range args {
+			argv = append(argv, a.Args...)
+			lines = append(lines, fmt.Sprintf("%-40s %s", gethargs.QuoteCommand(a.Args), strings.Join(fieldTabs[a.Field], ", ")))
+		}
+
+		command.SetText(envCommand(env, argv))
+		if len(lines) == 0 {
+			sources.SetText("No flags set, geth will run with its defaults.")
+		} else {
+			sources.SetText(strings.Join(lines, "\n"))
+		}
+
+		var text []string
+		if w := gethbin.Check(argv[1:], bin.Version); len(w) > 0 {
+			text = append(text, "Not supported by geth "+bin.Version.String()+":\n"+strings.Join(w, "\n"))
+		}
+		if len(dropped) > 0 {
+			text = append(text, "Left out, geth does not define "+droppedFlags(dropped))
+		}
+		if len(text) > 0 {
+			warnings.SetText(strings.Join(text, "\n\n"))
+			warnings.Show()
+		} else {
+			warnings.Hide()
+		}
+	}
+
+	listener := binding.NewDataListener(refresh)
+	for _, b := range bindings {
+		b.AddListener(listener)
+	}
+	binary.AddListener(listener)
+	help.AddListener(listener)
+
+	copyButton := widget.NewButton("Copy command", func() {
+		window.Clipboard().SetContent(envCommand(env, argv))
+	})
+	copyScriptButton := widget.NewButton("Copy as shell script", func() {
+		window.Clipboard().SetContent(shellScript(env, argv))
+	})
+
+	return container.NewVBox(
+		command,
+		warnings,
+		container.NewHBox(copyButton, copyScriptButton),
+		widget.NewLabel("Flags by tab"),
+		sources,
+	)
+}
+
+// envCommand renders argv as a single shell command, preceded by the
+// NAME=value assignments in env.
+func envCommand(env []config.EnvVar, argv []string) string {
+	words := make([]string, 0, len(env)+1)
+	for _, v := range env {
+		words = append(words, gethargs.QuoteAssignment(v.Name, v.Value))
+	}
+	return strings.Join(append(words, gethargs.QuoteCommand(argv)), " ")
+}
+
+// shellScript renders argv as a POSIX shell script that exports env and
+// runs geth from the GETH path, one flag per line.
+func shellScript(env []config.EnvVar, argv []string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# geth command line generated by GeNe\n")
+	fmt.Fprintf(&b, "GETH=${GETH:-%s}\n", gethargs.Quote(argv[0]))
+	for _, v := range env {
+		fmt.Fprintf(&b, "export %s\n", gethargs.QuoteAssignment(v.Name, v.Value))
+	}
+	b.WriteString("\n")
+	b.WriteString(`exec "$GETH"`)
+
+	args := argv[1:]
+	for i := 0; i < len(args); i++ {
+		b.WriteString(" \\\n  ")
+		b.WriteString(gethargs.Quote(args[i]))
+		// keep a flag and its value on the same line
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			i++
+			b.WriteString(" " + gethargs.Quote(args[i]))
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
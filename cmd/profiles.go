@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+)
+
+// profileBar lets the user load, save, save-as, duplicate and delete named
+// configuration profiles.
+type profileBar struct {
+	store  *config.ProfileStore
+	window fyne.Window
+	read   func() config.UserInputForNodeConfig
+	write  func(config.UserInputForNodeConfig)
+
+	picker  *widget.Select
+	current string
+}
+
+// newProfileBar builds the profile toolbar and restores the last used profile.
+func newProfileBar(store *config.ProfileStore, window fyne.Window, read func() config.UserInputForNodeConfig, write func(config.UserInputForNodeConfig)) fyne.CanvasObject {
+	p := &profileBar{
+		store:  store,
+		window: window,
+		read:   read,
+		write:  write,
+	}
+
+	p.picker = widget.NewSelect(nil, func(name string) {
+		if name != "" && name != p.current {
+			p.load(name)
+		}
+	})
+	p.picker.PlaceHolder = "(no profile)"
+	p.reload()
+
+	if last, err := store.LastUsed(); err == nil {
+		p.load(last)
+	} else if !errors.Is(err, config.ErrNoProfile) {
+		fmt.Println("Error reading last used profile:", err)
+	}
+
+	return container.NewHBox(
+		widget.NewLabel("Profile"),
+		p.picker,
+		widget.NewButton("Load", func() {
+			if p.current != "" {
+				p.load(p.current)
+			}
+		}),
+		widget.NewButton("Save", p.save),
+		widget.NewButton("Save As", p.saveAs),
+		widget.NewButton("Duplicate", p.duplicate),
+		widget.NewButton("Delete", p.delete),
+	)
+}
+
+// reload refreshes the list of profiles in the picker.
+func (p *profileBar) reload() {
+	names, err := p.store.List()
+	if err != nil {
+		dialog.ShowError(err, p.window)
+		return
+	}
+	p.picker.Options = names
+	p.picker.Refresh()
+}
+
+// selectProfile marks name as the current profile without reloading it.
+func (p *profileBar) selectProfile(name string) {
+	p.current = name
+	p.picker.SetSelected(name)
+	if err := p.store.SetLastUsed(name); err != nil {
+		fmt.Println("Error saving last used profile:", err)
+	}
+}
+
+func (p *profileBar) load(name string) {
+	cfg, err := p.store.Load(name)
+	if err != nil {
+		dialog.ShowError(err, p.window)
+		return
+	}
+	p.write(cfg)
+	p.selectProfile(name)
+}
+
+func (p *profileBar) save() {
+	if p.current == "" {
+		p.saveAs()
+		return
+	}
+	if err := p.store.Save(p.current, p.read()); err != nil {
+		dialog.ShowError(err, p.window)
+	}
+}
+
+func (p *profileBar) saveAs() {
+	p.askName("Save profile as", func(name string) {
+		if err := p.store.Save(name, p.read()); err != nil {
+			dialog.ShowError(err, p.window)
+			return
+		}
+		p.reload()
+		p.selectProfile(name)
+	})
+}
+
+func (p *profileBar) duplicate() {
+	if p.current == "" {
+		dialog.ShowInformation("Duplicate profile", "Select a profile to duplicate first.", p.window)
+		return
+	}
+	p.askName(fmt.Sprintf("Duplicate %q as", p.current), func(name string) {
+		if err := p.store.Duplicate(p.current, name); err != nil {
+			dialog.ShowError(err, p.window)
+			return
+		}
+		p.reload()
+		p.load(name)
+	})
+}
+
+func (p *profileBar) delete() {
+	if p.current == "" {
+		return
+	}
+	name := p.current
+	dialog.ShowConfirm("Delete profile", fmt.Sprintf("Delete profile %q?", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := p.store.Delete(name); err != nil {
+			dialog.ShowError(err, p.window)
+			return
+		}
+		p.current = ""
+		p.picker.ClearSelected()
+		p.reload()
+	}, p.window)
+}
+
+// askName prompts for a new profile name and passes it to fn once it is valid.
+func (p *profileBar) askName(title string, fn func(name string)) {
+	entry := widget.NewEntry()
+	entry.Validator = config.ValidateProfileName
+	dialog.ShowForm(title, "OK", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", entry),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		if p.store.Exists(entry.Text) {
+			dialog.ShowConfirm("Overwrite profile", fmt.Sprintf("Profile %q already exists. Overwrite it?", entry.Text), func(overwrite bool) {
+				if overwrite {
+					fn(entry.Text)
+				}
+			}, p.window)
+			return
+		}
+		fn(entry.Text)
+	}, p.window)
+}
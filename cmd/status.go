@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/rpc"
+	"gene/internal/syncstat"
+)
+
+// statusPollInterval is how often the Status tab polls the followed node.
+const statusPollInterval = 2 * time.Second
+
+// defaultSyncMode is the sync mode geth uses when SyncMode is empty.
+const defaultSyncMode = "snap"
+
+// syncStatus shows the sync progress of a running node in the Status tab.
+type syncStatus struct {
+	// mu guards tracker, which the poll and the node picker both use
+	mu      sync.Mutex
+	tracker syncstat.Tracker
+
+	summary  binding.String
+	syncMode binding.String
+	head     binding.String
+	blocks   binding.String
+	fraction binding.Float
+	rate     binding.String
+	eta      binding.String
+	peers    binding.String
+	states   binding.String
+	snap     binding.String
+	heal     binding.String
+	updated  binding.String
+}
+
+// newStatusTab builds the "Status" tab, which follows the sync of the node
+// picked from the nodes of the Nodes tab.
+func newStatusTab(manager *nodeManager) fyne.CanvasObject {
+	s := &syncStatus{
+		summary:  binding.NewString(),
+		syncMode: binding.NewString(),
+		head:     binding.NewString(),
+		blocks:   binding.NewString(),
+		fraction: binding.NewFloat(),
+		rate:     binding.NewString(),
+		eta:      binding.NewString(),
+		peers:    binding.NewString(),
+		states:   binding.NewString(),
+		snap:     binding.NewString(),
+		heal:     binding.NewString(),
+		updated:  binding.NewString(),
+	}
+	s.clear()
+	follower := newNodeFollower(manager, statusPollInterval, s.poll, func(reason string) {
+		s.summary.Set(reason)
+	}, s.clear)
+
+	summary := widget.NewLabelWithData(s.summary)
+	summary.Wrapping = fyne.TextWrapWord
+	form := widget.NewForm(
+		widget.NewFormItem("Sync mode", widget.NewLabelWithData(s.syncMode)),
+		widget.NewFormItem("Head block", widget.NewLabelWithData(s.head)),
+		widget.NewFormItem("Current / highest block", widget.NewLabelWithData(s.blocks)),
+		widget.NewFormItem("Progress", widget.NewProgressBarWithData(s.fraction)),
+		widget.NewFormItem("Blocks per second", widget.NewLabelWithData(s.rate)),
+		widget.NewFormItem("Estimated time left", widget.NewLabelWithData(s.eta)),
+		widget.NewFormItem("Peers", widget.NewLabelWithData(s.peers)),
+		widget.NewFormItem("State entries (pulled / known)", widget.NewLabelWithData(s.states)),
+		widget.NewFormItem("Snap sync (accounts / storage slots / bytecodes)", widget.NewLabelWithData(s.snap)),
+		widget.NewFormItem("Trie healing (healed / pending)", widget.NewLabelWithData(s.heal)),
+		widget.NewFormItem("Last update", widget.NewLabelWithData(s.updated)),
+	)
+	return container.NewVBox(
+		widget.NewForm(widget.NewFormItem("Node", follower.picker)),
+		summary,
+		form,
+	)
+}
+
+// poll reads the sync progress of the node and updates the tab.
+func (s *syncStatus) poll(ctx context.Context, client *rpc.Client, cfg config.UserInputForNodeConfig) error {
+	sample, err := syncstat.Fetch(ctx, client)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.tracker.Add(sample)
+	rate := s.tracker.BlocksPerSecond()
+	eta, etaOK := s.tracker.ETA()
+	s.mu.Unlock()
+
+	mode := cfg.SyncMode
+	if mode == "" {
+		mode = defaultSyncMode
+	}
+	s.syncMode.Set(mode)
+	s.head.Set(fmt.Sprint(sample.Block))
+	s.blocks.Set(fmt.Sprintf("%d / %d", sample.Current(), sample.Highest()))
+	s.fraction.Set(sample.Fraction())
+	s.rate.Set(fmt.Sprintf("%.2f", rate))
+	s.peers.Set(fmt.Sprint(sample.Peers))
+	s.updated.Set(sample.Time.Format("15:04:05"))
+
+	p := sample.Progress
+	if p == nil {
+		s.summary.Set("Synced")
+		s.eta.Set("-")
+		s.states.Set("-")
+		s.snap.Set("-")
+		s.heal.Set("-")
+		return nil
+	}
+
+	if etaOK {
+		s.eta.Set(formatETA(eta))
+	} else {
+		s.eta.Set("unknown until blocks are imported")
+	}
+	s.states.Set(fmt.Sprintf("%d / %d", p.PulledStates, p.KnownStates))
+	s.snap.Set(fmt.Sprintf("%d / %d / %d", p.SyncedAccounts, p.SyncedStorage, p.SyncedBytecodes))
+	s.heal.Set(fmt.Sprintf("%d / %d", p.HealedTrienodes, p.HealingTrienodes))
+	summary := fmt.Sprintf("Syncing, %.2f%% of the blocks", 100*sample.Fraction())
+	if sample.Peers == 0 {
+		summary += ", waiting for peers"
+	}
+	s.summary.Set(summary)
+	return nil
+}
+
+// clear forgets the samples and blanks the tab, e.g. when another node is picked.
+func (s *syncStatus) clear() {
+	s.mu.Lock()
+	s.tracker.Reset()
+	s.mu.Unlock()
+	for _, b := range []binding.String{s.syncMode, s.head, s.blocks, s.rate, s.eta, s.peers, s.states, s.snap, s.heal, s.updated} {
+		b.Set("-")
+	}
+	s.fraction.Set(0)
+}
+
+// formatETA rounds d for display, counting days since a snap sync can take several.
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	if days > 0 {
+		return fmt.Sprintf("%dd %s", days, d.Round(time.Minute))
+	}
+	return d.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+)
+
+// newTOMLButtons builds the buttons that export the form as a geth TOML
+// config file and import such a file back into the form.
+func newTOMLButtons(window fyne.Window, read func() config.UserInputForNodeConfig, write func(config.UserInputForNodeConfig)) fyne.CanvasObject {
+	exportButton := widget.NewButton("Export TOML", func() {
+		cfg := read()
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if w == nil {
+				return
+			}
+			defer w.Close()
+
+			if err := config.ExportTOML(w, cfg); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if skipped := config.UnexportedTOMLFields(cfg); len(skipped) > 0 {
+				dialog.ShowInformation("TOML exported",
+					fmt.Sprintf("Saved to %s.\n\nThese settings have no TOML equivalent and were left out:\n%s",
+						w.URI().Path(), strings.Join(skipped, ", ")), window)
+			}
+		}, window)
+		save.SetFileName("geth.toml")
+		save.SetFilter(storage.NewExtensionFileFilter([]string{".toml"}))
+		save.Show()
+	})
+
+	importButton := widget.NewButton("Import TOML", func() {
+		open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if r == nil {
+				return
+			}
+			defer r.Close()
+
+			cfg, err := config.ImportTOML(r, read())
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", r.URI().Path(), err), window)
+				return
+			}
+			write(cfg)
+		}, window)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".toml"}))
+		open.Show()
+	})
+
+	return container.NewHBox(exportButton, importButton)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"gene/internal/config"
+	"gene/internal/gethargs"
+)
+
+// fieldValidator returns an Entry validator reporting the config.Validate
+// problems for one field. The entry text is validated together with the
+// rest of the form so collisions between fields are caught as well.
+func fieldValidator(read func() config.UserInputForNodeConfig, field string) fyne.StringValidator {
+	return func(s string) error {
+		cfg := read()
+		// the binding may not have caught up with the entry yet
+		reflect.ValueOf(&cfg).Elem().FieldByName(field).SetString(s)
+		return config.Validate(cfg).For(field)
+	}
+}
+
+// extraArgsValidator returns the validator for the extra arguments entry,
+// which has to be split into words before gethargs can check it.
+func extraArgsValidator(read func() config.UserInputForNodeConfig) fyne.StringValidator {
+	return func(s string) error {
+		words, err := gethargs.Split(s)
+		if err != nil {
+			return err
+		}
+		cfg := read()
+		cfg.ExtraArgs = words
+		return gethargs.ValidateExtraArgs(cfg).For("ExtraArgs")
+	}
+}
+
+// markInvalidEntries flags every entry that has a problem in errs and
+// clears the flag on the others.
+func markInvalidEntries(entries map[string]*widget.Entry, errs config.ValidationErrors) {
+	for field, e := range entries {
+		e.SetValidationError(errs.For(field))
+	}
+}
@@ -0,0 +1,126 @@
+// Package config describes the user supplied configuration of a geth node.
+package config
+
+// UserInputForNodeConfig holds everything the user can configure for a node
+// in the GeNe forms. Empty values are left for geth to default.
+type UserInputForNodeConfig struct {
+	// TOMLConfig points to a TOML configuration file
+	TOMLConfig string
+
+	// HTTPEnabled Enable the HTTP-RPC server
+	HTTPEnabled bool
+	// HTTPAddr HTTP-RPC server listening interface (default: "localhost")
+	HTTPAddr string
+	// RPCHTTPPort HTTP-RPC server listening port (default: 8545)
+	RPCHTTPPort string
+	// RPCHTTPSelectedAPIMethods HTTP-RPC API modules
+	RPCHTTPSelectedAPIMethods []string
+	// HTTPCorsDomain Comma separated list of domains from which to accept cross origin requests (browser enforced)
+	HTTPCorsDomain string
+	// HTTPVirtualHosts Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard. (default: "localhost")
+	HTTPVirtualHosts string
+
+	// WSEnabled Enable the WS-RPC server
+	WSEnabled bool
+
+	// WSRPCInterface WS-RPC server listening interface (default: "localhost")
+	WSRPCInterface string
+	// WSRPCHTTPPort WS-RPC server listening port (default: 8546)
+	WSRPCHTTPPort string
+	// WSRPCOrigins WS-RPC allowed origins list (default: "[]")
+	WSRPCOrigins string
+	// WSRPCAPIs API's offered over the WS-RPC interface
+	WSRPCAPIs []string
+
+	// GraphQLEnabled Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.
+	GraphQLEnabled bool
+	// GraphQLCors Comma separated list of domains from which to accept cross origin requests (browser enforced)
+	GraphQLCors string
+	// GraphQLVirtualHosts Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard. (default: "localhost")
+	GraphQLVirtualHosts string
+
+	// AdminAddr Listening address for authenticated APIs (default: "localhost")
+	AdminAddr string
+	// AdminPort Listening port for authenticated APIs (default: 8551)
+	AdminPort string
+
+	// PreloadJS Comma separated list of JavaScript files to preload into the console
+	PreloadJS string
+	// ExecJS  Execute JavaScript statement
+	ExecJS string
+
+	// DBEndpoint URL for remote database
+	DBEndpoint string
+	// TxLookupLimit Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain) (default: 2350000)
+	TxLookupLimit string
+	// SyncMode Blockchain sync mode ("snap", "full" or "light") (default: snap)
+	SyncMode string
+	// NetworkID Explicitly set network id (integer)(For testnets: use --sepolia, --goerli instead) (default: 1)
+	NetworkID string
+	// P2PPort Network listening port (default: 30303)
+	P2PPort string
+	// DataDir Data directory for the databases and keystore (default: "~/.ethereum")
+	DataDir string
+	// IPCDisable Disable the IPC-RPC server
+	IPCDisable bool
+	// IPCPath Filename for IPC socket/pipe within the datadir (explicit paths escape it)
+	IPCPath string
+
+	// UserAddress Public address for block mining rewards (default = first account) (default: "0")
+	UserAddress string
+	// MinerThreads Number of CPU threads to use for mining (default: 0 = use all available cores)
+	MinerThreads string
+	// NotifyURLs Comma separated HTTP URL list to notify of new work packages
+	NotifyURLs string
+	// MinerMinimumGasPrice Minimum gas price for mining a transaction (default: 1000000000)
+	MinerMinimumGasPrice string
+	// MinerGasTarget Target gas floor for mined blocks, ignored since London (default: 8000000)
+	MinerGasTarget string
+	// MinerGasLimit Target gas ceiling for mined blocks (default: 30000000)
+	MinerGasLimit string
+	// MinerExtraData Block extra data set by the miner (default = client version)
+	MinerExtraData string
+	// MinerRecommit Time interval to recreate the block being mined (default: 3s)
+	MinerRecommit string
+	// MinerDisableRemoteSealing Disable remote sealing verification
+	MinerDisableRemoteSealing bool
+
+	// DeveloperMode Flag to enable Ephemeral proof-of-authority network with a pre-funded developer account, mining enabled
+	DeveloperMode bool
+	// DeveloperPeriod Block period to use in developer mode (0 = mine only if transaction pending) (default: 0)
+	DeveloperPeriod string
+	// DeveloperGasLimit Initial block gas limit (default: 11500000)
+	DeveloperGasLimit string
+
+	// ExtraArgs Additional geth command line arguments that GeNe has no field for, appended as is
+	ExtraArgs []string
+	// Env Environment variables set for the geth process on top of GeNe's own environment
+	Env []EnvVar
+
+	// Binary Label of the registered geth binary to run, empty for the one GeNe found on startup
+	Binary string
+
+	// RestartPolicy Restart geth when it exits unexpectedly ("never", "on-failure" or "always") (default: never)
+	RestartPolicy string
+	// MaxRestarts Number of automatic restarts allowed within RestartWindow before giving up (default: 5)
+	MaxRestarts string
+	// RestartWindow Period over which automatic restarts are counted (default: 10m)
+	RestartWindow string
+	// ReadyTimeout How long to wait for geth to answer on its IPC or HTTP endpoint after launch (default: 1m)
+	ReadyTimeout string
+}
+
+// EnvVar is an environment variable passed to the geth process.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// Environ returns the variables in the NAME=value form used by exec.Cmd.Env.
+func Environ(vars []EnvVar) []string {
+	env := make([]string, len(vars))
+	for i, v := range vars {
+		env[i] = v.Name + "=" + v.Value
+	}
+	return env
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// defaultIPCName is the name of the IPC socket geth creates in its datadir.
+const defaultIPCName = "geth.ipc"
+
+// Endpoint returns the endpoint GeNe uses to reach the node it launches
+// with cfg: the IPC socket, which needs no open port and serves every API,
+// unless IPC is disabled, then the HTTP-RPC server, then the WebSocket
+// server. It is empty when geth offers none of them.
+func Endpoint(cfg UserInputForNodeConfig) string {
+	for _, e := range []string{IPCEndpoint(cfg), HTTPEndpoint(cfg), WSEndpoint(cfg)} {
+		if e != "" {
+			return e
+		}
+	}
+	return ""
+}
+
+// HTTPEndpoint returns the URL of the HTTP-RPC server geth opens for cfg,
+// empty when the server is disabled. A server listening on all interfaces
+// is reached through the loopback address.
+func HTTPEndpoint(cfg UserInputForNodeConfig) string {
+	if !cfg.HTTPEnabled {
+		return ""
+	}
+	return rpcURL("http", cfg.HTTPAddr, cfg.RPCHTTPPort, DefaultHTTPPort)
+}
+
+// WSEndpoint returns the URL of the WebSocket-RPC server geth opens for
+// cfg, empty when the server is disabled.
+func WSEndpoint(cfg UserInputForNodeConfig) string {
+	if !cfg.WSEnabled {
+		return ""
+	}
+	return rpcURL("ws", cfg.WSRPCInterface, cfg.WSRPCHTTPPort, DefaultWSPort)
+}
+
+func rpcURL(scheme, host, port string, def int) string {
+	p := def
+	if port != "" {
+		v, err := strconv.Atoi(port)
+		if err != nil || v < 1 || v > 65535 {
+			return ""
+		}
+		p = v
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(dialHost(orDefault(host, defaultRPCHost)), strconv.Itoa(p)))
+}
+
+// IPCEndpoint returns the path of the IPC socket geth opens for cfg, empty
+// when IPC is disabled. As in geth, an IPCPath without a directory names a
+// socket in the datadir. It is also empty on Windows, where geth uses a
+// named pipe instead of a socket.
+func IPCEndpoint(cfg UserInputForNodeConfig) string {
+	if cfg.IPCDisable || runtime.GOOS == "windows" {
+		return ""
+	}
+	path := orDefault(cfg.IPCPath, defaultIPCName)
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if filepath.Base(path) != path {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		return filepath.Clean(path)
+	}
+	dir := DataDir(cfg)
+	if dir == "" {
+		// geth puts the socket of a temporary datadir in the temp directory
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, path)
+}
+
+// dialHost returns the address to connect to for a server listening on host.
+func dialHost(host string) string {
+	switch host {
+	case "0.0.0.0", "":
+		return "127.0.0.1"
+	case "::":
+		return "::1"
+	}
+	return host
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NodeEntry is a node GeNe runs next to the one edited in the form.
+type NodeEntry struct {
+	// Name identifies the node in the Nodes tab
+	Name string
+	// Profile is the name of the profile the node is started from
+	Profile string
+}
+
+// NodeStore keeps the list of nodes in a JSON file.
+type NodeStore struct {
+	// File is the JSON file holding the entries
+	File string
+}
+
+// DefaultNodeStore returns the store under the user configuration
+// directory, e.g. ~/.config/gene/nodes.json on Linux.
+func DefaultNodeStore() (*NodeStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &NodeStore{File: filepath.Join(dir, "gene", "nodes.json")}, nil
+}
+
+// Load returns the stored nodes, in the order they were added.
+func (s *NodeStore) Load() ([]NodeEntry, error) {
+	data, err := os.ReadFile(s.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nodes []NodeEntry
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.File, err)
+	}
+	return nodes, nil
+}
+
+// Save replaces the stored nodes.
+func (s *NodeStore) Save(nodes []NodeEntry) error {
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.File), 0o700); err != nil {
+		return err
+	}
+	tmp := s.File + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.File)
+}
+
+// Port is a TCP port geth listens on.
+type Port struct {
+	// Field is the UserInputForNodeConfig field setting the port
+	Field string
+	// Host is the interface geth listens on, empty for all interfaces
+	Host string
+	Port int
+}
+
+// ListenPorts returns the ports geth opens for cfg, using geth's default
+// for every field left empty. Invalid ports, which Validate reports, are
+// left out.
+func ListenPorts(cfg UserInputForNodeConfig) []Port {
+	var ports []Port
+	for _, p := range portFields(cfg) {
+		if !p.enabled {
+			continue
+		}
+		port := p.def
+		if p.value != "" {
+			v, err := strconv.Atoi(p.value)
+			if err != nil || v < 1 || v > 65535 {
+				continue
+			}
+			port = v
+		}
+		ports = append(ports, Port{Field: p.field, Host: p.host, Port: port})
+	}
+	return ports
+}
+
+// DataDir returns the data directory geth uses for cfg as a clean absolute
+// path, geth's default directory when none is set. It is empty in developer
+// mode without a datadir, where geth uses a fresh temporary directory.
+func DataDir(cfg UserInputForNodeConfig) string {
+	dir := cfg.DataDir
+	if dir == "" {
+		if cfg.DeveloperMode {
+			return ""
+		}
+		dir = defaultDataDir()
+	}
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Clean(dir)
+}
+
+// defaultDataDir mirrors node.DefaultDataDir in go-ethereum.
+func defaultDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Ethereum")
+	case "windows":
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			return filepath.Join(local, "Ethereum")
+		}
+		return filepath.Join(home, "AppData", "Roaming", "Ethereum")
+	default:
+		return filepath.Join(home, ".ethereum")
+	}
+}
+
+// Collision is a data directory or port claimed by more than one node.
+type Collision struct {
+	// Resource describes what is shared, e.g. "port 30303" or "datadir /data"
+	Resource string
+	// Nodes are the names of the nodes sharing it, sorted
+	Nodes []string
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("%s is used by %s", c.Resource, strings.Join(c.Nodes, ", "))
+}
+
+// Involves reports whether the node called name is part of the collision.
+func (c Collision) Involves(name string) bool {
+	for _, n := range c.Nodes {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Collisions returns the data directories, ports and IPC sockets shared by
+// the nodes, keyed by node name. Collisions within a single node are left
+// to Validate.
+func Collisions(nodes map[string]UserInputForNodeConfig) []Collision {
+	users := make(map[string]map[string]bool)
+	claim := func(resource, node string) {
+		if users[resource] == nil {
+			users[resource] = make(map[string]bool)
+		}
+		users[resource][node] = true
+	}
+	for name, cfg := range nodes {
+		if dir := DataDir(cfg); dir != "" {
+			claim("datadir "+dir, name)
+		}
+		for _, p := range ListenPorts(cfg) {
+			claim(fmt.Sprintf("port %d", p.Port), name)
+		}
+		if ipc := IPCEndpoint(cfg); ipc != "" {
+			claim("IPC socket "+ipc, name)
+		}
+	}
+
+	var collisions []Collision
+	for resource, names := range users {
+		if len(names) < 2 {
+			continue
+		}
+		c := Collision{Resource: resource}
+		for n := range names {
+			c.Nodes = append(c.Nodes, n)
+		}
+		sort.Strings(c.Nodes)
+		collisions = append(collisions, c)
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i].Resource < collisions[j].Resource })
+	return collisions
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	profileExt   = ".json"
+	lastUsedFile = "last-profile"
+)
+
+// ErrNoProfile is returned by ProfileStore.LastUsed when no profile has been used yet.
+var ErrNoProfile = errors.New("no profile has been used yet")
+
+// ProfileStore keeps named UserInputForNodeConfig profiles as JSON files in a directory.
+type ProfileStore struct {
+	// Dir is the directory holding one <name>.json file per profile
+	Dir string
+}
+
+// DefaultProfileStore returns the store under the user configuration
+// directory, e.g. ~/.config/gene/profiles on Linux.
+func DefaultProfileStore() (*ProfileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileStore{Dir: filepath.Join(dir, "gene", "profiles")}, nil
+}
+
+// ValidateProfileName checks that name can be used as a profile file name.
+func ValidateProfileName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return errors.New("profile name is empty")
+	case name != strings.TrimSpace(name):
+		return fmt.Errorf("profile name %q has leading or trailing spaces", name)
+	case strings.ContainsAny(name, `/\:`) || name == "." || name == "..":
+		return fmt.Errorf("profile name %q contains a path separator", name)
+	}
+	return nil
+}
+
+func (s *ProfileStore) path(name string) string {
+	return filepath.Join(s.Dir, name+profileExt)
+}
+
+// List returns the names of the stored profiles, sorted.
+func (s *ProfileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), profileExt) {
+			names = append(names, strings.TrimSuffix(e.Name(), profileExt))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Exists reports whether a profile with the given name is stored.
+func (s *ProfileStore) Exists(name string) bool {
+	_, err := os.Stat(s.path(name))
+	return err == nil
+}
+
+// Load reads the named profile.
+func (s *ProfileStore) Load(name string) (UserInputForNodeConfig, error) {
+	var cfg UserInputForNodeConfig
+	if err := ValidateProfileName(name); err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// Save writes cfg as the named profile, replacing any existing one.
+func (s *ProfileStore) Save(name string, cfg UserInputForNodeConfig) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a crash never leaves a truncated profile behind
+	tmp := s.path(name) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(name))
+}
+
+// Duplicate copies the profile from into the profile named to, replacing any existing one.
+func (s *ProfileStore) Duplicate(from, to string) error {
+	cfg, err := s.Load(from)
+	if err != nil {
+		return err
+	}
+	return s.Save(to, cfg)
+}
+
+// Delete removes the named profile. Deleting the last used profile also
+// forgets it as last used.
+func (s *ProfileStore) Delete(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(name)); err != nil {
+		return err
+	}
+	if last, err := s.LastUsed(); err == nil && last == name {
+		return os.Remove(filepath.Join(s.Dir, lastUsedFile))
+	}
+	return nil
+}
+
+// LastUsed returns the name of the profile that was last loaded or saved.
+func (s *ProfileStore) LastUsed() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, lastUsedFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoProfile
+	}
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(data))
+	if !s.Exists(name) {
+		return "", ErrNoProfile
+	}
+	return name, nil
+}
+
+// SetLastUsed records name as the profile to restore on the next start.
+func (s *ProfileStore) SetLastUsed(name string) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, lastUsedFile), []byte(name+"\n"), 0o600)
+}
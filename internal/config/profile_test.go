@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileStore(t *testing.T) {
+	s := &ProfileStore{Dir: filepath.Join(t.TempDir(), "profiles")}
+
+	// a store that was never written to is empty
+	if names, err := s.List(); err != nil || len(names) != 0 {
+		t.Fatalf("List of a new store = %v, %v", names, err)
+	}
+	if _, err := s.LastUsed(); !errors.Is(err, ErrNoProfile) {
+		t.Fatalf("LastUsed of a new store = %v, want ErrNoProfile", err)
+	}
+
+	mainnet := UserInputForNodeConfig{
+		DataDir:                   "/data/mainnet",
+		P2PPort:                   "30303",
+		HTTPEnabled:               true,
+		RPCHTTPSelectedAPIMethods: []string{"eth", "net", "web3"},
+		ExtraArgs:                 []string{"--cache", "4096"},
+	}
+	dev := UserInputForNodeConfig{DeveloperMode: true, IPCDisable: true}
+	if err := s.Save("mainnet", mainnet); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save("dev", dev); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Load("mainnet"); err != nil || !reflect.DeepEqual(got, mainnet) {
+		t.Errorf("Load(mainnet) = %+v, %v, want %+v", got, err, mainnet)
+	}
+	if names, err := s.List(); err != nil || !reflect.DeepEqual(names, []string{"dev", "mainnet"}) {
+		t.Errorf("List = %v, %v, want [dev mainnet]", names, err)
+	}
+
+	// saving again replaces the profile
+	mainnet.P2PPort = "30313"
+	if err := s.Save("mainnet", mainnet); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Load("mainnet"); got.P2PPort != "30313" {
+		t.Errorf("P2PPort after the second Save = %q, want 30313", got.P2PPort)
+	}
+
+	if err := s.Duplicate("mainnet", "mainnet copy"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Load("mainnet copy"); err != nil || !reflect.DeepEqual(got, mainnet) {
+		t.Errorf("Load of the duplicate = %+v, %v, want %+v", got, err, mainnet)
+	}
+	if err := s.Duplicate("missing", "other"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Duplicate of a missing profile = %v, want fs.ErrNotExist", err)
+	}
+
+	if err := s.SetLastUsed("mainnet"); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.LastUsed(); err != nil || last != "mainnet" {
+		t.Errorf("LastUsed = %q, %v, want mainnet", last, err)
+	}
+
+	// deleting another profile keeps the last used one
+	if err := s.Delete("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.LastUsed(); err != nil || last != "mainnet" {
+		t.Errorf("LastUsed after deleting dev = %q, %v, want mainnet", last, err)
+	}
+	if s.Exists("dev") {
+		t.Error("dev still exists after Delete")
+	}
+
+	// deleting the last used profile forgets it
+	if err := s.Delete("mainnet"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LastUsed(); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("LastUsed after deleting it = %v, want ErrNoProfile", err)
+	}
+	if names, err := s.List(); err != nil || !reflect.DeepEqual(names, []string{"mainnet copy"}) {
+		t.Errorf("List = %v, %v, want [mainnet copy]", names, err)
+	}
+	if err := s.Delete("mainnet"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("second Delete = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"mainnet", true},
+		{"goerli archive", true},
+		{"", false},
+		{"   ", false},
+		{" padded", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"c:", false},
+		{".", false},
+		{"..", false},
+	}
+	for _, tt := range tests {
+		err := ValidateProfileName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateProfileName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+
+	s := &ProfileStore{Dir: t.TempDir()}
+	if err := s.Save("../escape", UserInputForNodeConfig{}); err == nil {
+		t.Error("Save accepted a name with a path separator")
+	}
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The types below mirror the subset of the `geth dumpconfig` layout that
+// UserInputForNodeConfig can express. Pointer fields distinguish keys that
+// are absent from the file from keys set to their zero value.
+
+type gethTOML struct {
+	Eth  *ethTOML  `toml:",omitempty"`
+	Node *nodeTOML `toml:",omitempty"`
+}
+
+type ethTOML struct {
+	NetworkId     *uint64    `toml:",omitempty"`
+	SyncMode      *string    `toml:",omitempty"`
+	TxLookupLimit *uint64    `toml:",omitempty"`
+	Miner         *minerTOML `toml:",omitempty"`
+}
+
+type minerTOML struct {
+	Etherbase *string   `toml:",omitempty"`
+	Notify    *[]string `toml:",omitempty"`
+	ExtraData *string   `toml:",omitempty"`
+	GasFloor  *uint64   `toml:",omitempty"`
+	GasCeil   *uint64   `toml:",omitempty"`
+	GasPrice  *uint64   `toml:",omitempty"`
+	Recommit  *int64    `toml:",omitempty"`
+	Noverify  *bool     `toml:",omitempty"`
+}
+
+type nodeTOML struct {
+	DataDir             *string   `toml:",omitempty"`
+	IPCPath             *string   // empty disables IPC, so it is never omitted when set
+	HTTPHost            *string   `toml:",omitempty"`
+	HTTPPort            *int      `toml:",omitempty"`
+	HTTPCors            *[]string `toml:",omitempty"`
+	HTTPVirtualHosts    *[]string `toml:",omitempty"`
+	HTTPModules         *[]string `toml:",omitempty"`
+	AuthAddr            *string   `toml:",omitempty"`
+	AuthPort            *int      `toml:",omitempty"`
+	WSHost              *string   `toml:",omitempty"`
+	WSPort              *int      `toml:",omitempty"`
+	WSOrigins           *[]string `toml:",omitempty"`
+	WSModules           *[]string `toml:",omitempty"`
+	GraphQLCors         *[]string `toml:",omitempty"`
+	GraphQLVirtualHosts *[]string `toml:",omitempty"`
+	P2P                 *p2pTOML  `toml:",omitempty"`
+}
+
+type p2pTOML struct {
+	ListenAddr *string `toml:",omitempty"`
+}
+
+// defaultRPCHost is the interface geth listens on when --http or --ws is
+// given without an address.
+const defaultRPCHost = "localhost"
+
+// TOMLOnlyFlags lists the UserInputForNodeConfig fields that have no
+// equivalent in a geth TOML file and have to stay command line flags, or
+// in the case of Env, the environment geth is started with.
+var TOMLOnlyFlags = []string{
+	"TOMLConfig",
+	"GraphQLEnabled",
+	"PreloadJS",
+	"ExecJS",
+	"DBEndpoint",
+	"MinerThreads",
+	"DeveloperMode",
+	"DeveloperPeriod",
+	"DeveloperGasLimit",
+	"ExtraArgs",
+	"Env",
+}
+
+// ExportTOML writes cfg to w as a geth TOML config file with [Eth],
+// [Eth.Miner], [Node] and [Node.P2P] sections, as `geth dumpconfig` would.
+// Fields that geth only accepts as flags are not written; see TOMLOnlyFlags.
+func ExportTOML(w io.Writer, cfg UserInputForNodeConfig) error {
+	var (
+		eth   ethTOML
+		miner minerTOML
+		node  nodeTOML
+		p2p   p2pTOML
+		err   error
+	)
+
+	if eth.NetworkId, err = uintField("NetworkID", cfg.NetworkID); err != nil {
+		return err
+	}
+	eth.SyncMode = stringField(cfg.SyncMode)
+	if eth.TxLookupLimit, err = uintField("TxLookupLimit", cfg.TxLookupLimit); err != nil {
+		return err
+	}
+
+	miner.Etherbase = stringField(cfg.UserAddress)
+	miner.Notify = listField(cfg.NotifyURLs)
+	if cfg.MinerExtraData != "" {
+		extra := "0x" + hex.EncodeToString([]byte(cfg.MinerExtraData))
+		miner.ExtraData = &extra
+	}
+	if miner.GasFloor, err = uintField("MinerGasTarget", cfg.MinerGasTarget); err != nil {
+		return err
+	}
+	if miner.GasCeil, err = uintField("MinerGasLimit", cfg.MinerGasLimit); err != nil {
+		return err
+	}
+	if miner.GasPrice, err = uintField("MinerMinimumGasPrice", cfg.MinerMinimumGasPrice); err != nil {
+		return err
+	}
+	if cfg.MinerRecommit != "" {
+		d, err := time.ParseDuration(cfg.MinerRecommit)
+		if err != nil {
+			return fmt.Errorf("MinerRecommit: %w", err)
+		}
+		ns := int64(d)
+		miner.Recommit = &ns
+	}
+	if cfg.MinerDisableRemoteSealing {
+		miner.Noverify = &cfg.MinerDisableRemoteSealing
+	}
+
+	node.DataDir = stringField(cfg.DataDir)
+	if cfg.IPCDisable {
+		// geth disables IPC when the path is empty
+		none := ""
+		node.IPCPath = &none
+	} else {
+		node.IPCPath = stringField(cfg.IPCPath)
+	}
+	if cfg.HTTPEnabled {
+		host := cfg.HTTPAddr
+		if host == "" {
+			host = defaultRPCHost
+		}
+		node.HTTPHost = &host
+	}
+	if node.HTTPPort, err = portField("RPCHTTPPort", cfg.RPCHTTPPort); err != nil {
+		return err
+	}
+	node.HTTPCors = listField(cfg.HTTPCorsDomain)
+	node.HTTPVirtualHosts = listField(cfg.HTTPVirtualHosts)
+	if len(cfg.RPCHTTPSelectedAPIMethods) > 0 {
+		node.HTTPModules = &cfg.RPCHTTPSelectedAPIMethods
+	}
+	node.AuthAddr = stringField(cfg.AdminAddr)
+	if node.AuthPort, err = portField("AdminPort", cfg.AdminPort); err != nil {
+		return err
+	}
+	if cfg.WSEnabled {
+		host := cfg.WSRPCInterface
+		if host == "" {
+			host = defaultRPCHost
+		}
+		node.WSHost = &host
+	}
+	if node.WSPort, err = portField("WSRPCHTTPPort", cfg.WSRPCHTTPPort); err != nil {
+		return err
+	}
+	node.WSOrigins = listField(cfg.WSRPCOrigins)
+	if len(cfg.WSRPCAPIs) > 0 {
+		node.WSModules = &cfg.WSRPCAPIs
+	}
+	node.GraphQLCors = listField(cfg.GraphQLCors)
+	node.GraphQLVirtualHosts = listField(cfg.GraphQLVirtualHosts)
+
+	if cfg.P2PPort != "" {
+		if _, err := portField("P2PPort", cfg.P2PPort); err != nil {
+			return err
+		}
+		addr := ":" + cfg.P2PPort
+		p2p.ListenAddr = &addr
+	}
+
+	var out gethTOML
+	if miner != (minerTOML{}) {
+		eth.Miner = &miner
+	}
+	if eth != (ethTOML{}) {
+		out.Eth = &eth
+	}
+	if p2p != (p2pTOML{}) {
+		node.P2P = &p2p
+	}
+	if node != (nodeTOML{}) {
+		out.Node = &node
+	}
+
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	return enc.Encode(out)
+}
+
+// UnexportedTOMLFields returns the fields listed in TOMLOnlyFlags that are
+// set in cfg, i.e. the settings an exported TOML file will be missing.
+func UnexportedTOMLFields(cfg UserInputForNodeConfig) []string {
+	v := reflect.ValueOf(cfg)
+	var fields []string
+	for _, name := range TOMLOnlyFlags {
+		if !v.FieldByName(name).IsZero() {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// ImportTOML reads a geth TOML config file and applies every setting it
+// understands on top of base. Settings missing from the file keep their
+// value from base, and sections or keys GeNe does not model are ignored.
+func ImportTOML(r io.Reader, base UserInputForNodeConfig) (UserInputForNodeConfig, error) {
+	var in gethTOML
+	if _, err := toml.NewDecoder(r).Decode(&in); err != nil {
+		return base, err
+	}
+	cfg := base
+
+	if eth := in.Eth; eth != nil {
+		setUint(&cfg.NetworkID, eth.NetworkId)
+		setString(&cfg.SyncMode, eth.SyncMode)
+		setUint(&cfg.TxLookupLimit, eth.TxLookupLimit)
+
+		if miner := eth.Miner; miner != nil {
+			setString(&cfg.UserAddress, miner.Etherbase)
+			setList(&cfg.NotifyURLs, miner.Notify)
+			if miner.ExtraData != nil {
+				extra, err := hex.DecodeString(strings.TrimPrefix(*miner.ExtraData, "0x"))
+				if err != nil {
+					return base, fmt.Errorf("Eth.Miner.ExtraData: %w", err)
+				}
+				cfg.MinerExtraData = string(extra)
+			}
+			setUint(&cfg.MinerGasTarget, miner.GasFloor)
+			setUint(&cfg.MinerGasLimit, miner.GasCeil)
+			setUint(&cfg.MinerMinimumGasPrice, miner.GasPrice)
+			if miner.Recommit != nil {
+				cfg.MinerRecommit = time.Duration(*miner.Recommit).String()
+			}
+			if miner.Noverify != nil {
+				cfg.MinerDisableRemoteSealing = *miner.Noverify
+			}
+		}
+	}
+
+	if node := in.Node; node != nil {
+		setString(&cfg.DataDir, node.DataDir)
+		if node.IPCPath != nil {
+			cfg.IPCDisable = *node.IPCPath == ""
+			cfg.IPCPath = *node.IPCPath
+		}
+		if node.HTTPHost != nil {
+			cfg.HTTPEnabled = *node.HTTPHost != ""
+			cfg.HTTPAddr = *node.HTTPHost
+		}
+		setInt(&cfg.RPCHTTPPort, node.HTTPPort)
+		setList(&cfg.HTTPCorsDomain, node.HTTPCors)
+		setList(&cfg.HTTPVirtualHosts, node.HTTPVirtualHosts)
+		if node.HTTPModules != nil {
+			cfg.RPCHTTPSelectedAPIMethods = *node.HTTPModules
+		}
+		setString(&cfg.AdminAddr, node.AuthAddr)
+		setInt(&cfg.AdminPort, node.AuthPort)
+		if node.WSHost != nil {
+			cfg.WSEnabled = *node.WSHost != ""
+			cfg.WSRPCInterface = *node.WSHost
+		}
+		setInt(&cfg.WSRPCHTTPPort, node.WSPort)
+		setList(&cfg.WSRPCOrigins, node.WSOrigins)
+		if node.WSModules != nil {
+			cfg.WSRPCAPIs = *node.WSModules
+		}
+		setList(&cfg.GraphQLCors, node.GraphQLCors)
+		setList(&cfg.GraphQLVirtualHosts, node.GraphQLVirtualHosts)
+
+		if node.P2P != nil && node.P2P.ListenAddr != nil {
+			_, port, err := net.SplitHostPort(*node.P2P.ListenAddr)
+			if err != nil {
+				return base, fmt.Errorf("Node.P2P.ListenAddr: %w", err)
+			}
+			cfg.P2PPort = port
+		}
+	}
+
+	return cfg, nil
+}
+
+func stringField(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func uintField(name, s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &v, nil
+}
+
+func portField(name, s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	port := int(v)
+	return &port, nil
+}
+
+// listField splits a comma separated form value into a TOML list.
+func listField(s string) *[]string {
+	if s == "" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return &list
+}
+
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setUint(dst *string, v *uint64) {
+	if v != nil {
+		*dst = strconv.FormatUint(*v, 10)
+	}
+}
+
+func setInt(dst *string, v *int) {
+	if v != nil {
+		*dst = strconv.Itoa(*v)
+	}
+}
+
+func setList(dst *string, v *[]string) {
+	if v != nil {
+		*dst = strings.Join(*v, ",")
+	}
+}
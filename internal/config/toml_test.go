@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTOMLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  UserInputForNodeConfig
+	}{
+		{name: "empty"},
+		{
+			name: "every TOML setting",
+			cfg: UserInputForNodeConfig{
+				HTTPEnabled:               true,
+				HTTPAddr:                  "0.0.0.0",
+				RPCHTTPPort:               "8545",
+				RPCHTTPSelectedAPIMethods: []string{"eth", "net", "web3"},
+				HTTPCorsDomain:            "https://example.org,http://localhost:3000",
+				HTTPVirtualHosts:          "localhost,example.org",
+				WSEnabled:                 true,
+				WSRPCInterface:            "127.0.0.1",
+				WSRPCHTTPPort:             "8546",
+				WSRPCOrigins:              "*",
+				WSRPCAPIs:                 []string{"eth"},
+				GraphQLCors:               "*",
+				GraphQLVirtualHosts:       "localhost",
+				AdminAddr:                 "localhost",
+				AdminPort:                 "8551",
+				TxLookupLimit:             "0",
+				SyncMode:                  "full",
+				NetworkID:                 "1337",
+				P2PPort:                   "30304",
+				DataDir:                   "/home/user/my chain",
+				IPCPath:                   "/tmp/geth.ipc",
+				UserAddress:               "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				NotifyURLs:                "http://pool:8000,http://pool:8001",
+				MinerMinimumGasPrice:      "1000000000",
+				MinerGasTarget:            "30000000",
+				MinerGasLimit:             "36000000",
+				MinerExtraData:            "GeNe \"node\" 1",
+				MinerRecommit:             "2.5s",
+				MinerDisableRemoteSealing: true,
+			},
+		},
+		{
+			name: "IPC disabled",
+			cfg:  UserInputForNodeConfig{IPCDisable: true, DataDir: "/data"},
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := ExportTOML(&buf, tt.cfg); err != nil {
+			t.Errorf("%s: ExportTOML: %v", tt.name, err)
+			continue
+		}
+		got, err := ImportTOML(&buf, UserInputForNodeConfig{})
+		if err != nil {
+			t.Errorf("%s: ImportTOML: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.cfg) {
+			t.Errorf("%s: round trip\n got %+v\nwant %+v", tt.name, got, tt.cfg)
+		}
+	}
+}
+
+func TestExportTOML(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := UserInputForNodeConfig{
+		IPCDisable:     true,
+		IPCPath:        "ignored.ipc",
+		MinerExtraData: "GeNe",
+		HTTPEnabled:    true,
+		P2PPort:        "30313",
+		MinerThreads:   "4",
+	}
+	if err := ExportTOML(&buf, cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := `[Eth]
+[Eth.Miner]
+ExtraData = "0x47654e65"
+
+[Node]
+IPCPath = ""
+HTTPHost = "localhost"
+[Node.P2P]
+ListenAddr = ":30313"
+`
+	if got := buf.String(); got != want {
+		t.Errorf("ExportTOML =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnexportedTOMLFields(cfg); !reflect.DeepEqual(got, []string{"MinerThreads"}) {
+		t.Errorf("UnexportedTOMLFields = %v, want [MinerThreads]", got)
+	}
+
+	for _, bad := range []UserInputForNodeConfig{
+		{NetworkID: "one"},
+		{RPCHTTPPort: "70000"},
+		{MinerRecommit: "3"},
+		{P2PPort: "port"},
+	} {
+		if err := ExportTOML(&buf, bad); err == nil {
+			t.Errorf("ExportTOML(%+v) accepted an invalid value", bad)
+		}
+	}
+}
+
+func TestImportDumpconfig(t *testing.T) {
+	f, err := os.Open("testdata/dumpconfig.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	base := UserInputForNodeConfig{
+		HTTPCorsDomain: "https://example.org",
+		WSEnabled:      true,
+		WSRPCInterface: "0.0.0.0",
+		MinerThreads:   "4",
+		IPCPath:        "geth.ipc",
+		ExtraArgs:      []string{"--nodiscover"},
+	}
+	got, err := ImportTOML(f, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UserInputForNodeConfig{
+		// settings the file does not have keep their value from base
+		HTTPCorsDomain: "https://example.org",
+		MinerThreads:   "4",
+		ExtraArgs:      []string{"--nodiscover"},
+
+		NetworkID:            "11155111",
+		SyncMode:             "snap",
+		TxLookupLimit:        "2350000",
+		UserAddress:          "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		MinerExtraData:       "GeNe",
+		MinerMinimumGasPrice: "1000000000",
+		MinerGasLimit:        "30000000",
+		MinerRecommit:        "3s",
+
+		DataDir:    "/home/user/.ethereum/sepolia",
+		IPCDisable: true,
+		IPCPath:    "",
+
+		HTTPEnabled:               true,
+		HTTPAddr:                  "127.0.0.1",
+		RPCHTTPPort:               "8545",
+		HTTPVirtualHosts:          "localhost",
+		RPCHTTPSelectedAPIMethods: []string{"net", "web3", "eth"},
+		AdminAddr:                 "localhost",
+		AdminPort:                 "8551",
+		// an empty WSHost turns the WS server off
+		WSEnabled:           false,
+		WSRPCInterface:      "",
+		WSRPCHTTPPort:       "8546",
+		WSRPCAPIs:           []string{"net", "web3", "eth"},
+		GraphQLVirtualHosts: "localhost",
+		P2PPort:             "30313",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportTOML\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestImportTOMLErrors(t *testing.T) {
+	base := UserInputForNodeConfig{DataDir: "/data"}
+	for _, in := range []string{
+		"[Eth\n",
+		"[Eth.Miner]\nExtraData = \"0xzz\"\n",
+		"[Node.P2P]\nListenAddr = \"30303\"\n",
+		"[Node]\nHTTPPort = \"8545\"\n",
+	} {
+		got, err := ImportTOML(strings.NewReader(in), base)
+		if err == nil {
+			t.Errorf("ImportTOML(%q) accepted an invalid file", in)
+		}
+		if !reflect.DeepEqual(got, base) {
+			t.Errorf("ImportTOML(%q) = %+v on error, want base", in, got)
+		}
+	}
+}
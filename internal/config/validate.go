@@ -0,0 +1,304 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// SyncModes lists the values geth accepts for --syncmode.
+var SyncModes = []string{"snap", "full", "light"}
+
+// Default ports geth listens on when no port is configured.
+const (
+	DefaultHTTPPort = 8545
+	DefaultWSPort   = 8546
+	DefaultAuthPort = 8551
+	DefaultP2PPort  = 30303
+)
+
+// FieldError reports an invalid value in a UserInputForNodeConfig field.
+type FieldError struct {
+	// Field is the name of the UserInputForNodeConfig field
+	Field string
+	// Message explains what is wrong with the value
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is the list of problems found by Validate.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// For returns the problems found with the given field, joined into one
+// error, or nil if the field is valid.
+func (v ValidationErrors) For(field string) error {
+	var msgs []string
+	for _, e := range v {
+		if e.Field == field {
+			msgs = append(msgs, e.Message)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// Validate checks cfg for values geth would reject, so they can be reported
+// next to the offending field instead of as a fatal error from geth.
+// Empty fields are always valid since geth falls back to its defaults.
+func Validate(cfg UserInputForNodeConfig) ValidationErrors {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	used := make(map[int][]string)
+	for _, p := range portFields(cfg) {
+		port := p.def
+		if p.value != "" {
+			v, err := strconv.Atoi(p.value)
+			if err != nil || v < 1 || v > 65535 {
+				add(p.field, "port must be a number between 1 and 65535")
+				continue
+			}
+			port = v
+		}
+		if p.enabled {
+			used[port] = append(used[port], p.field)
+		}
+	}
+	for port, fields := range used {
+		// geth serves HTTP and WS from a single listener when they share a port
+		if len(fields) == 2 && fields[0] == "RPCHTTPPort" && fields[1] == "WSRPCHTTPPort" {
+			continue
+		}
+		if len(fields) > 1 {
+			for _, f := range fields {
+				add(f, "port %d is also used by %s", port, strings.Join(without(fields, f), ", "))
+			}
+		}
+	}
+
+	if cfg.UserAddress != "" {
+		if err := ValidateAddress(cfg.UserAddress); err != nil {
+			add("UserAddress", "%s", err)
+		}
+	}
+
+	if cfg.MinerRecommit != "" {
+		if _, err := time.ParseDuration(cfg.MinerRecommit); err != nil {
+			add("MinerRecommit", "must be a duration such as 3s or 500ms")
+		}
+	}
+	if cfg.RestartWindow != "" {
+		if _, err := time.ParseDuration(cfg.RestartWindow); err != nil {
+			add("RestartWindow", "must be a duration such as 10m or 1h")
+		}
+	}
+	if cfg.ReadyTimeout != "" {
+		if d, err := time.ParseDuration(cfg.ReadyTimeout); err != nil || d <= 0 {
+			add("ReadyTimeout", "must be a positive duration such as 1m or 90s")
+		}
+	}
+
+	for _, f := range []struct{ field, value string }{
+		{"MinerMinimumGasPrice", cfg.MinerMinimumGasPrice},
+		{"MinerGasTarget", cfg.MinerGasTarget},
+		{"MinerGasLimit", cfg.MinerGasLimit},
+		{"DeveloperGasLimit", cfg.DeveloperGasLimit},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if v, ok := new(big.Int).SetString(f.value, 10); !ok || v.Sign() < 0 {
+			add(f.field, "must be a whole, non-negative number of wei or gas")
+		}
+	}
+
+	for _, f := range []struct{ field, value string }{
+		{"NetworkID", cfg.NetworkID},
+		{"TxLookupLimit", cfg.TxLookupLimit},
+		{"MinerThreads", cfg.MinerThreads},
+		{"DeveloperPeriod", cfg.DeveloperPeriod},
+		{"MaxRestarts", cfg.MaxRestarts},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(f.value, 10, 64); err != nil {
+			add(f.field, "must be a whole, non-negative number")
+		}
+	}
+
+	if cfg.SyncMode != "" && !contains(SyncModes, cfg.SyncMode) {
+		add("SyncMode", "must be one of %s", strings.Join(SyncModes, ", "))
+	}
+
+	seenEnv := make(map[string]bool)
+	for _, v := range cfg.Env {
+		switch {
+		case !isEnvName(v.Name):
+			add("Env", "%q is not a valid environment variable name", v.Name)
+		case seenEnv[v.Name]:
+			add("Env", "%s is set more than once", v.Name)
+		}
+		seenEnv[v.Name] = true
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// portSetting is a port setting of a UserInputForNodeConfig.
+type portSetting struct {
+	field   string
+	value   string
+	def     int
+	enabled bool
+	// host is the interface geth listens on, empty for all interfaces
+	host string
+}
+
+// portFields lists the port settings of cfg with geth's default for each,
+// and whether geth opens the port at all.
+func portFields(cfg UserInputForNodeConfig) []portSetting {
+	return []portSetting{
+		{"RPCHTTPPort", cfg.RPCHTTPPort, DefaultHTTPPort, cfg.HTTPEnabled, orDefault(cfg.HTTPAddr, defaultRPCHost)},
+		{"WSRPCHTTPPort", cfg.WSRPCHTTPPort, DefaultWSPort, cfg.WSEnabled, orDefault(cfg.WSRPCInterface, defaultRPCHost)},
+		{"AdminPort", cfg.AdminPort, DefaultAuthPort, true, orDefault(cfg.AdminAddr, defaultRPCHost)},
+		{"P2PPort", cfg.P2PPort, DefaultP2PPort, true, ""},
+	}
+}
+
+// ValidateEnode checks that s is a node URL geth can connect to:
+// enode://<128 hex digit public key>@host:port, or an "enr:" record.
+func ValidateEnode(s string) error {
+	if strings.HasPrefix(s, "enr:") {
+		return nil
+	}
+	if !strings.HasPrefix(s, "enode://") {
+		return fmt.Errorf("node URL must start with enode:// or enr:")
+	}
+	id, addr, ok := strings.Cut(strings.TrimPrefix(s, "enode://"), "@")
+	if !ok {
+		return fmt.Errorf("node URL must name the host after @")
+	}
+	if len(id) != 128 {
+		return fmt.Errorf("node ID must have 128 hex digits, got %d", len(id))
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return fmt.Errorf("node ID is not valid hex")
+	}
+	addr, _, _ = strings.Cut(addr, "?")
+	if _, port, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("node URL must end with host:port: %w", err)
+	} else if v, err := strconv.Atoi(port); err != nil || v < 1 || v > 65535 {
+		return fmt.Errorf("%q is not a valid port", port)
+	}
+	return nil
+}
+
+// ValidateAddress checks that addr is a 20 byte hex address and, if it
+// uses mixed case, that it carries a valid EIP-55 checksum.
+func ValidateAddress(addr string) error {
+	if !strings.HasPrefix(addr, "0x") && !strings.HasPrefix(addr, "0X") {
+		return fmt.Errorf("address must start with 0x")
+	}
+	digits := addr[2:]
+	if len(digits) != 40 {
+		return fmt.Errorf("address must have 40 hex digits, got %d", len(digits))
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return fmt.Errorf("address is not valid hex")
+	}
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if want := ChecksumAddress(addr); addr[2:] != want[2:] {
+		return fmt.Errorf("invalid EIP-55 checksum, expected %s", want)
+	}
+	return nil
+}
+
+// ChecksumAddress returns addr in its EIP-55 mixed case form. addr must be
+// a 0x prefixed, 40 digit hex address.
+func ChecksumAddress(addr string) string {
+	lower := strings.ToLower(addr[2:])
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+
+	out := []byte(lower)
+	for i, c := range out {
+		// uppercase a letter when the matching nibble of the hash is 8 or more
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && c <= 'f' && nibble&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// isEnvName reports whether s is a portable environment variable name.
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func without(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
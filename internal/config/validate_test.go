@@ -0,0 +1,356 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// eip55Vectors are the test vectors of EIP-55.
+var eip55Vectors = []string{
+	// all caps
+	"0x52908400098527886E0F7030069857D2E4169EE7",
+	"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+	// all lower
+	"0xde709f2102306220921060314715629080e2fb77",
+	"0x27b1fdb04752bbc536007a920d24acb045561c26",
+	// normal
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestChecksumAddress(t *testing.T) {
+	for _, want := range eip55Vectors {
+		for _, in := range []string{want, "0x" + strings.ToLower(want[2:]), "0x" + strings.ToUpper(want[2:])} {
+			if got := ChecksumAddress(in); got != want {
+				t.Errorf("ChecksumAddress(%s) = %s, want %s", in, got, want)
+			}
+		}
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	for _, addr := range eip55Vectors {
+		if err := ValidateAddress(addr); err != nil {
+			t.Errorf("ValidateAddress(%s): %v", addr, err)
+		}
+	}
+	for _, addr := range []string{
+		// single case addresses carry no checksum
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0X5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+	} {
+		if err := ValidateAddress(addr); err != nil {
+			t.Errorf("ValidateAddress(%s): %v", addr, err)
+		}
+	}
+
+	tests := []struct {
+		addr string
+		err  string
+	}{
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "must start with 0x"},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", "40 hex digits, got 38"},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAedaa", "40 hex digits, got 42"},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", "not valid hex"},
+		// one letter of the last vector with the wrong case
+		{"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDB", "expected 0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"},
+		{"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "invalid EIP-55 checksum"},
+	}
+	for _, tt := range tests {
+		err := ValidateAddress(tt.addr)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ValidateAddress(%s) = %v, want an error containing %q", tt.addr, err, tt.err)
+		}
+	}
+}
+
+// fieldsOf returns the fields errs reports, with the message of each.
+func fieldsOf(errs ValidationErrors) map[string]string {
+	fields := make(map[string]string)
+	for _, e := range errs {
+		if fields[e.Field] != "" {
+			fields[e.Field] += "; "
+		}
+		fields[e.Field] += e.Message
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  UserInputForNodeConfig
+		want map[string]string
+	}{
+		{
+			name: "empty",
+			want: map[string]string{},
+		},
+		{
+			name: "valid values",
+			cfg: UserInputForNodeConfig{
+				HTTPEnabled:          true,
+				RPCHTTPPort:          "8545",
+				WSEnabled:            true,
+				WSRPCHTTPPort:        "8546",
+				AdminPort:            "8551",
+				P2PPort:              "30303",
+				UserAddress:          "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				MinerRecommit:        "500ms",
+				RestartWindow:        "1h",
+				ReadyTimeout:         "90s",
+				MinerMinimumGasPrice: "1000000000000000000000",
+				MinerGasTarget:       "0",
+				MinerGasLimit:        "30000000",
+				DeveloperGasLimit:    "11500000",
+				NetworkID:            "1337",
+				TxLookupLimit:        "0",
+				MinerThreads:         "4",
+				DeveloperPeriod:      "5",
+				MaxRestarts:          "3",
+				SyncMode:             "light",
+				Env:                  []EnvVar{{Name: "GOGC", Value: "50"}, {Name: "_x1", Value: ""}},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "invalid ports",
+			cfg:  UserInputForNodeConfig{RPCHTTPPort: "0", WSRPCHTTPPort: "65536", AdminPort: "http", P2PPort: "-1"},
+			want: map[string]string{
+				"RPCHTTPPort":   "port must be a number between 1 and 65535",
+				"WSRPCHTTPPort": "port must be a number between 1 and 65535",
+				"AdminPort":     "port must be a number between 1 and 65535",
+				"P2PPort":       "port must be a number between 1 and 65535",
+			},
+		},
+		{
+			name: "HTTP and WS may share a port",
+			cfg:  UserInputForNodeConfig{HTTPEnabled: true, WSEnabled: true, RPCHTTPPort: "8545", WSRPCHTTPPort: "8545"},
+			want: map[string]string{},
+		},
+		{
+			name: "disabled servers claim no port",
+			cfg:  UserInputForNodeConfig{RPCHTTPPort: "30303", WSRPCHTTPPort: "8551"},
+			want: map[string]string{},
+		},
+		{
+			name: "HTTP port collides with the default P2P port",
+			cfg:  UserInputForNodeConfig{HTTPEnabled: true, RPCHTTPPort: "30303"},
+			want: map[string]string{
+				"RPCHTTPPort": "port 30303 is also used by P2PPort",
+				"P2PPort":     "port 30303 is also used by RPCHTTPPort",
+			},
+		},
+		{
+			name: "WS port collides with the auth port",
+			cfg:  UserInputForNodeConfig{WSEnabled: true, WSRPCHTTPPort: "9000", AdminPort: "9000"},
+			want: map[string]string{
+				"WSRPCHTTPPort": "port 9000 is also used by AdminPort",
+				"AdminPort":     "port 9000 is also used by WSRPCHTTPPort",
+			},
+		},
+		{
+			name: "three fields on one port",
+			cfg:  UserInputForNodeConfig{HTTPEnabled: true, WSEnabled: true, RPCHTTPPort: "9000", WSRPCHTTPPort: "9000", P2PPort: "9000"},
+			want: map[string]string{
+				"RPCHTTPPort":   "port 9000 is also used by WSRPCHTTPPort, P2PPort",
+				"WSRPCHTTPPort": "port 9000 is also used by RPCHTTPPort, P2PPort",
+				"P2PPort":       "port 9000 is also used by RPCHTTPPort, WSRPCHTTPPort",
+			},
+		},
+		{
+			name: "durations",
+			cfg:  UserInputForNodeConfig{MinerRecommit: "3", RestartWindow: "ten minutes", ReadyTimeout: "0s"},
+			want: map[string]string{
+				"MinerRecommit": "must be a duration such as 3s or 500ms",
+				"RestartWindow": "must be a duration such as 10m or 1h",
+				"ReadyTimeout":  "must be a positive duration such as 1m or 90s",
+			},
+		},
+		{
+			name: "negative ready timeout",
+			cfg:  UserInputForNodeConfig{ReadyTimeout: "-1m"},
+			want: map[string]string{"ReadyTimeout": "must be a positive duration such as 1m or 90s"},
+		},
+		{
+			name: "numbers",
+			cfg: UserInputForNodeConfig{
+				MinerMinimumGasPrice: "-1",
+				MinerGasTarget:       "1e6",
+				MinerGasLimit:        "-1",
+				DeveloperGasLimit:    "lots",
+				NetworkID:            "0x1",
+				TxLookupLimit:        "-5",
+				MinerThreads:         "1.5",
+				DeveloperPeriod:      "5s",
+				MaxRestarts:          "many",
+			},
+			want: map[string]string{
+				"MinerMinimumGasPrice": "must be a whole, non-negative number of wei or gas",
+				"MinerGasTarget":       "must be a whole, non-negative number of wei or gas",
+				"MinerGasLimit":        "must be a whole, non-negative number of wei or gas",
+				"DeveloperGasLimit":    "must be a whole, non-negative number of wei or gas",
+				"NetworkID":            "must be a whole, non-negative number",
+				"TxLookupLimit":        "must be a whole, non-negative number",
+				"MinerThreads":         "must be a whole, non-negative number",
+				"DeveloperPeriod":      "must be a whole, non-negative number",
+				"MaxRestarts":          "must be a whole, non-negative number",
+			},
+		},
+		{
+			name: "sync modes",
+			cfg:  UserInputForNodeConfig{SyncMode: "fast"},
+			want: map[string]string{"SyncMode": "must be one of snap, full, light"},
+		},
+		{
+			name: "address",
+			cfg:  UserInputForNodeConfig{UserAddress: "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+			want: map[string]string{"UserAddress": "invalid EIP-55 checksum, expected 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		},
+		{
+			name: "environment",
+			cfg:  UserInputForNodeConfig{Env: []EnvVar{{Name: "1X"}, {Name: "A-B"}, {Name: "GOGC", Value: "1"}, {Name: "GOGC", Value: "2"}}},
+			want: map[string]string{"Env": `"1X" is not a valid environment variable name; "A-B" is not a valid environment variable name; GOGC is set more than once`},
+		},
+	}
+	for _, tt := range tests {
+		errs := Validate(tt.cfg)
+		if got := fieldsOf(errs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Validate = %q, want %q", tt.name, got, tt.want)
+		}
+		for i := 1; i < len(errs); i++ {
+			if errs[i-1].Field > errs[i].Field {
+				t.Errorf("%s: errors are not sorted by field: %v", tt.name, errs)
+			}
+		}
+	}
+}
+
+func TestValidationErrorsFor(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "UserAddress", Message: "a"},
+		{Field: "P2PPort", Message: "b"},
+		{Field: "UserAddress", Message: "c"},
+	}
+	if err := errs.For("UserAddress"); err == nil || err.Error() != "a; c" {
+		t.Errorf("For(UserAddress) = %v, want a; c", err)
+	}
+	if err := errs.For("SyncMode"); err != nil {
+		t.Errorf("For(SyncMode) = %v, want nil", err)
+	}
+	if got, want := errs.Error(), "UserAddress: a\nP2PPort: b\nUserAddress: c"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateEnode(t *testing.T) {
+	id := strings.Repeat("ab", 64)
+	for _, s := range []string{
+		"enode://" + id + "@127.0.0.1:30303",
+		"enode://" + id + "@[::1]:30303?discport=30301",
+		"enode://" + id + "@bootnode.example.org:30303",
+		"enr:-KO4QHFG1eDdnDSPYvDQ_K7dEcoz9uXEz0y9pDhS6Fu3hK3gXr6vQ9V5U4FQ1y9d4ZGmw",
+	} {
+		if err := ValidateEnode(s); err != nil {
+			t.Errorf("ValidateEnode(%s): %v", s, err)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"127.0.0.1:30303",
+		"enode://" + id,
+		"enode://abcd@127.0.0.1:30303",
+		"enode://" + strings.Repeat("zz", 64) + "@127.0.0.1:30303",
+		"enode://" + id + "@127.0.0.1",
+		"enode://" + id + "@127.0.0.1:0",
+	} {
+		if err := ValidateEnode(s); err == nil {
+			t.Errorf("ValidateEnode(%q) accepted an invalid node URL", s)
+		}
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	// node returns a config that shares nothing with the other nodes of the test
+	node := func(i int, edit func(*UserInputForNodeConfig)) UserInputForNodeConfig {
+		cfg := UserInputForNodeConfig{
+			DataDir:   "/data/node" + string(rune('0'+i)),
+			AdminPort: "855" + string(rune('0'+i)),
+			P2PPort:   "3030" + string(rune('0'+i)),
+		}
+		if edit != nil {
+			edit(&cfg)
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name  string
+		nodes map[string]UserInputForNodeConfig
+		want  []string
+	}{
+		{
+			name:  "separate nodes",
+			nodes: map[string]UserInputForNodeConfig{"a": node(1, nil), "b": node(2, nil)},
+		},
+		{
+			name: "shared datadir",
+			nodes: map[string]UserInputForNodeConfig{
+				"a": node(1, nil),
+				"b": node(2, func(c *UserInputForNodeConfig) { c.DataDir = "/data/node1/" }),
+			},
+			// the default socket lives in the datadir
+			want: []string{
+				"IPC socket /data/node1/geth.ipc is used by a, b",
+				"datadir /data/node1 is used by a, b",
+			},
+		},
+		{
+			name: "shared IPC socket",
+			nodes: map[string]UserInputForNodeConfig{
+				"a": node(1, func(c *UserInputForNodeConfig) { c.IPCPath = "/tmp/geth.ipc" }),
+				"b": node(2, func(c *UserInputForNodeConfig) { c.IPCPath = "/tmp/geth.ipc" }),
+				"c": node(3, func(c *UserInputForNodeConfig) { c.IPCPath = "/tmp/geth.ipc"; c.IPCDisable = true }),
+			},
+			want: []string{"IPC socket /tmp/geth.ipc is used by a, b"},
+		},
+		{
+			name: "default ports",
+			nodes: map[string]UserInputForNodeConfig{
+				"a": {DataDir: "/data/a"},
+				"b": {DataDir: "/data/b", HTTPEnabled: true},
+				"c": {DataDir: "/data/c", HTTPEnabled: true, RPCHTTPPort: "8645", P2PPort: "30304", AdminPort: "8651"},
+			},
+			want: []string{
+				"port 30303 is used by a, b",
+				"port 8551 is used by a, b",
+			},
+		},
+		{
+			name: "ports across fields",
+			nodes: map[string]UserInputForNodeConfig{
+				"a": node(1, func(c *UserInputForNodeConfig) { c.HTTPEnabled = true; c.RPCHTTPPort = "9000" }),
+				"b": node(2, func(c *UserInputForNodeConfig) { c.WSEnabled = true; c.WSRPCHTTPPort = "9000" }),
+				"c": node(3, func(c *UserInputForNodeConfig) { c.RPCHTTPPort = "9000" }),
+			},
+			want: []string{"port 9000 is used by a, b"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range Collisions(tt.nodes) {
+			got = append(got, c.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Collisions = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	c := Collision{Resource: "port 9000", Nodes: []string{"a", "b"}}
+	if !c.Involves("b") || c.Involves("c") {
+		t.Errorf("Involves is wrong for %v", c)
+	}
+}
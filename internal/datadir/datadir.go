@@ -0,0 +1,160 @@
+// Package datadir keeps two geth processes, or two GeNe instances, from
+// using the same geth data directory.
+package datadir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GethLockFile is the file geth locks while it runs. It lives in the
+// instance directory, "geth", inside the data directory rather than in the
+// data directory itself; see GethLockPath.
+const GethLockFile = "LOCK"
+
+// GethInstance is the directory geth keeps its node data in, inside the
+// data directory.
+const GethInstance = "geth"
+
+// GethLockPath returns the LOCK file geth holds for the data directory dir,
+// e.g. <dir>/geth/LOCK.
+func GethLockPath(dir string) string {
+	return filepath.Join(dir, GethInstance, GethLockFile)
+}
+
+// ErrUnsupported is returned by checks that are not available on this platform.
+var ErrUnsupported = errors.New("not supported on this platform")
+
+// Process is a running geth using a data directory.
+type Process struct {
+	PID int
+	// Command is the command line of the process
+	Command string
+}
+
+// ConflictError explains why a data directory cannot be used.
+type ConflictError struct {
+	// Dir is the data directory
+	Dir string
+	// Reason says who holds it
+	Reason string
+	// Processes are the geth processes found using it, if any
+	Processes []Process
+}
+
+func (e *ConflictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "datadir %s %s", e.Dir, e.Reason)
+	for _, p := range e.Processes {
+		fmt.Fprintf(&b, "\n  pid %d: %s", p.PID, p.Command)
+	}
+	return b.String()
+}
+
+// Lock is held by a GeNe instance for every data directory it drives.
+type Lock struct {
+	dir  string
+	file *os.File
+}
+
+// lockDir is where GeNe keeps its own locks, one per data directory, so
+// nothing is written into the data directory itself.
+func lockDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gene", "locks"), nil
+}
+
+// Acquire takes the GeNe lock for dir, failing with a *ConflictError when
+// another GeNe instance holds it.
+func Acquire(dir string) (*Lock, error) {
+	locks, err := lockDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(locks, 0o700); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(dir))
+	path := filepath.Join(locks, hex.EncodeToString(sum[:8])+".lock")
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		reason := "is driven by another GeNe instance"
+		if data, err := os.ReadFile(path); err == nil {
+			if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				reason += fmt.Sprintf(" (pid %d)", pid)
+			}
+		}
+		return nil, &ConflictError{Dir: dir, Reason: reason}
+	}
+
+	// record the owner for the instance that fails to get the lock
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return &Lock{dir: dir, file: f}, nil
+}
+
+// Dir returns the data directory the lock is held for.
+func (l *Lock) Dir() string {
+	return l.dir
+}
+
+// Release gives the lock up.
+func (l *Lock) Release() error {
+	return l.file.Close()
+}
+
+// Check reports a *ConflictError when a geth process uses dir, either
+// because the geth LOCK file, GethLockPath(dir), is held or because a running geth was
+// started with it as its datadir.
+func Check(dir string) error {
+	procs, err := FindGeth(dir)
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return err
+	}
+
+	locked, err := gethLocked(dir)
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return err
+	}
+	switch {
+	case locked:
+		return &ConflictError{Dir: dir, Reason: "is locked by a running geth (" + GethLockPath(dir) + ")", Processes: procs}
+	case len(procs) > 0:
+		return &ConflictError{Dir: dir, Reason: "is used by a running geth", Processes: procs}
+	}
+	return nil
+}
+
+// gethLocked reports whether a process holds the geth LOCK file of dir.
+func gethLocked(dir string) (bool, error) {
+	f, err := os.OpenFile(GethLockPath(dir), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err := tryLock(f); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, unlock(f)
+}
@@ -0,0 +1,50 @@
+package datadir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckGethLock(t *testing.T) {
+	dir := t.TempDir()
+	if err := Check(dir); err != nil {
+		t.Fatalf("Check on an unused datadir: %v", err)
+	}
+
+	// geth locks <datadir>/geth/LOCK, a LOCK in the datadir itself means nothing
+	if err := os.WriteFile(filepath.Join(dir, GethLockFile), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, GethInstance), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := GethLockPath(dir)
+	if want := filepath.Join(dir, "geth", "LOCK"); path != want {
+		t.Fatalf("GethLockPath = %s, want %s", path, want)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := Check(dir); err != nil {
+		t.Fatalf("Check with an unheld LOCK: %v", err)
+	}
+
+	if err := tryLock(f); errors.Is(err, ErrUnsupported) {
+		t.Skip("file locks are not supported on this platform")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	err = Check(dir)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Check with a held LOCK = %v, want a *ConflictError", err)
+	}
+	if !strings.Contains(conflict.Reason, path) {
+		t.Errorf("Reason %q does not name %s", conflict.Reason, path)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package datadir
+
+import "os"
+
+func tryLock(f *os.File) error {
+	return ErrUnsupported
+}
+
+func unlock(f *os.File) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package datadir
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock takes an exclusive flock on f without waiting, the same kind of
+// lock geth takes on its LOCK file.
+func tryLock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+func unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,75 @@
+package datadir
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gene/internal/config"
+	"gene/internal/gethargs"
+)
+
+// clientCommands are geth subcommands that do not open the datadir.
+var clientCommands = map[string]bool{
+	"attach":     true,
+	"version":    true,
+	"help":       true,
+	"license":    true,
+	"dumpconfig": true,
+	"account":    true,
+}
+
+// FindGeth returns the running geth processes whose datadir is dir, read
+// from /proc/<pid>/cmdline. A relative datadir is resolved against the
+// working directory of the process.
+func FindGeth(dir string) ([]Process, error) {
+	cmdlines, err := filepath.Glob("/proc/[0-9]*/cmdline")
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	var procs []Process
+	for _, file := range cmdlines {
+		procDir := filepath.Dir(file)
+		pid, err := strconv.Atoi(filepath.Base(procDir))
+		if err != nil || pid == self {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		argv := strings.Split(string(bytes.TrimRight(data, "\x00")), "\x00")
+		if !strings.Contains(filepath.Base(argv[0]), "geth") {
+			continue
+		}
+
+		p, err := gethargs.ParseCommandLine(gethargs.QuoteCommand(argv), config.UserInputForNodeConfig{})
+		if err != nil || runsClient(p.Config.ExtraArgs) {
+			continue
+		}
+		if p.Config.DataDir != "" && !filepath.IsAbs(p.Config.DataDir) && !strings.HasPrefix(p.Config.DataDir, "~") {
+			if cwd, err := os.Readlink(filepath.Join(procDir, "cwd")); err == nil {
+				p.Config.DataDir = filepath.Join(cwd, p.Config.DataDir)
+			}
+		}
+		if config.DataDir(p.Config) == dir {
+			procs = append(procs, Process{PID: pid, Command: strings.Join(argv, " ")})
+		}
+	}
+	return procs, nil
+}
+
+// runsClient reports whether the geth command line runs a subcommand that
+// does not use the datadir, e.g. "geth attach".
+func runsClient(args []string) bool {
+	for _, a := range args {
+		if clientCommands[a] {
+			return true
+		}
+	}
+	return false
+}
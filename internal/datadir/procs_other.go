@@ -0,0 +1,8 @@
+//go:build !linux
+
+package datadir
+
+// FindGeth is only implemented on Linux, where /proc is available.
+func FindGeth(dir string) ([]Process, error) {
+	return nil, ErrUnsupported
+}
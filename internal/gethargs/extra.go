@@ -0,0 +1,64 @@
+package gethargs
+
+import (
+	"fmt"
+	"strings"
+
+	"gene/internal/config"
+)
+
+// ValidateExtraArgs checks cfg.ExtraArgs. Extra arguments must start with
+// a flag, may not repeat a flag GeNe already has a field for, since geth
+// would silently take only one of them, and may not run a subcommand.
+func ValidateExtraArgs(cfg config.UserInputForNodeConfig) config.ValidationErrors {
+	var errs config.ValidationErrors
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, config.FieldError{Field: "ExtraArgs", Message: fmt.Sprintf(format, args...)})
+	}
+
+	byFlag := make(map[string]Mapping, len(Mappings))
+	for _, m := range Mappings {
+		byFlag[m.Flag] = m
+	}
+
+	for i, arg := range cfg.ExtraArgs {
+		if !strings.HasPrefix(arg, "-") {
+			// a value following a flag
+			if i > 0 && strings.HasPrefix(cfg.ExtraArgs[i-1], "-") && !strings.Contains(cfg.ExtraArgs[i-1], "=") {
+				continue
+			}
+			add("%q is not a flag; subcommands and positional arguments are not supported", arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name = name[:eq]
+		}
+		if alias, ok := legacyFlags[name]; ok {
+			name = alias
+		}
+		switch {
+		case name == "":
+			add("%q is not a flag", arg)
+		case byFlag[name].Field != "":
+			add("--%s is set by the %s field, use that instead", name, byFlag[name].Field)
+		}
+	}
+	return errs
+}
+
+// FormatArgLines renders args for editing, one flag per line together with
+// its value, quoted so Split reads them back unchanged.
+func FormatArgLines(args []string) string {
+	var lines []string
+	for i := 0; i < len(args); i++ {
+		line := Quote(args[i])
+		if strings.HasPrefix(args[i], "-") && !strings.Contains(args[i], "=") && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			line += " " + Quote(args[i])
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
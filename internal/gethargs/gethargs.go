@@ -0,0 +1,192 @@
+// Package gethargs converts a node configuration into geth command line arguments.
+package gethargs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gene/internal/config"
+)
+
+// Mapping ties a config.UserInputForNodeConfig field to the geth flag it sets.
+//
+// String fields emit "--flag value" when non-empty, []string fields emit
+// "--flag a,b,c" when non-empty and bool fields emit "--flag" when true.
+type Mapping struct {
+	// Field is the name of the config.UserInputForNodeConfig field
+	Field string
+	// Flag is the geth flag name without the leading dashes
+	Flag string
+}
+
+// Mappings lists every field that is passed to geth, in command line order.
+var Mappings = []Mapping{
+	{Field: "TOMLConfig", Flag: "config"},
+
+	{Field: "HTTPEnabled", Flag: "http"},
+	{Field: "HTTPAddr", Flag: "http.addr"},
+	{Field: "RPCHTTPPort", Flag: "http.port"},
+	{Field: "RPCHTTPSelectedAPIMethods", Flag: "http.api"},
+	{Field: "HTTPCorsDomain", Flag: "http.corsdomain"},
+	{Field: "HTTPVirtualHosts", Flag: "http.vhosts"},
+
+	{Field: "WSEnabled", Flag: "ws"},
+	{Field: "WSRPCInterface", Flag: "ws.addr"},
+	{Field: "WSRPCHTTPPort", Flag: "ws.port"},
+	{Field: "WSRPCOrigins", Flag: "ws.origins"},
+	{Field: "WSRPCAPIs", Flag: "ws.api"},
+
+	{Field: "GraphQLEnabled", Flag: "graphql"},
+	{Field: "GraphQLCors", Flag: "graphql.corsdomain"},
+	{Field: "GraphQLVirtualHosts", Flag: "graphql.vhosts"},
+
+	{Field: "AdminAddr", Flag: "authrpc.addr"},
+	{Field: "AdminPort", Flag: "authrpc.port"},
+
+	{Field: "PreloadJS", Flag: "preload"},
+	{Field: "ExecJS", Flag: "exec"},
+
+	{Field: "DBEndpoint", Flag: "db.endpoint"},
+	{Field: "TxLookupLimit", Flag: "txlookuplimit"},
+	{Field: "SyncMode", Flag: "syncmode"},
+	{Field: "NetworkID", Flag: "networkid"},
+	{Field: "P2PPort", Flag: "port"},
+	{Field: "DataDir", Flag: "datadir"},
+	{Field: "IPCDisable", Flag: "ipcdisable"},
+	{Field: "IPCPath", Flag: "ipcpath"},
+
+	{Field: "UserAddress", Flag: "miner.etherbase"},
+	{Field: "MinerThreads", Flag: "miner.threads"},
+	{Field: "NotifyURLs", Flag: "miner.notify"},
+	{Field: "MinerMinimumGasPrice", Flag: "miner.gasprice"},
+	{Field: "MinerGasTarget", Flag: "miner.gastarget"},
+	{Field: "MinerGasLimit", Flag: "miner.gaslimit"},
+	{Field: "MinerExtraData", Flag: "miner.extradata"},
+	{Field: "MinerRecommit", Flag: "miner.recommit"},
+	{Field: "MinerDisableRemoteSealing", Flag: "miner.noverify"},
+
+	{Field: "DeveloperMode", Flag: "dev"},
+	{Field: "DeveloperPeriod", Flag: "dev.period"},
+	{Field: "DeveloperGasLimit", Flag: "dev.gaslimit"},
+}
+
+// Unmapped lists the config.UserInputForNodeConfig fields that have no
+// Mapping. ExtraArgs is appended to the command line as is, Env is applied to
+// the geth process environment and the GeNeFields are used by GeNe itself.
+var Unmapped = append([]string{"ExtraArgs", "Env"}, GeNeFields...)
+
+func init() {
+	if err := checkMappings(); err != nil {
+		panic(err)
+	}
+}
+
+// checkMappings makes sure every field of config.UserInputForNodeConfig is
+// either mapped to a flag of a supported kind or explicitly unmapped, so a
+// new field cannot be silently dropped from the command line.
+func checkMappings() error {
+	t := reflect.TypeOf(config.UserInputForNodeConfig{})
+	seen := make(map[string]bool)
+
+	for _, m := range Mappings {
+		f, ok := t.FieldByName(m.Field)
+		if !ok {
+			return fmt.Errorf("gethargs: mapping for unknown field %s", m.Field)
+		}
+		switch {
+		case f.Type.Kind() == reflect.String, f.Type.Kind() == reflect.Bool:
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+		default:
+			return fmt.Errorf("gethargs: field %s has unsupported type %s", m.Field, f.Type)
+		}
+		seen[m.Field] = true
+	}
+	for _, name := range Unmapped {
+		seen[name] = true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Name; !seen[name] {
+			return fmt.Errorf("gethargs: field %s is neither mapped to a flag nor listed as unmapped", name)
+		}
+	}
+	return nil
+}
+
+// FlagFor returns the flag set by the config field called field.
+func FlagFor(field string) (string, bool) {
+	for _, m := range Mappings {
+		if m.Field == field {
+			return m.Flag, true
+		}
+	}
+	return "", false
+}
+
+// Arg is the part of a command line produced by a single Mapping.
+type Arg struct {
+	Mapping
+	// Args are the command line words, e.g. ["--http.port", "8545"]
+	Args []string
+}
+
+// Annotate returns the command line for cfg split by the Mapping each
+// argument came from, followed by cfg.ExtraArgs. Fields left empty are omitted.
+func Annotate(cfg config.UserInputForNodeConfig) []Arg {
+	v := reflect.ValueOf(cfg)
+
+	var args []Arg
+	for _, m := range Mappings {
+		flag := "--" + m.Flag
+		f := v.FieldByName(m.Field)
+		switch f.Kind() {
+		case reflect.String:
+			if s := f.String(); s != "" {
+				args = append(args, Arg{Mapping: m, Args: []string{flag, s}})
+			}
+		case reflect.Bool:
+			if f.Bool() {
+				args = append(args, Arg{Mapping: m, Args: []string{flag}})
+			}
+		case reflect.Slice:
+			if f.Len() > 0 {
+				args = append(args, Arg{Mapping: m, Args: []string{flag, strings.Join(f.Interface().([]string), ",")}})
+			}
+		}
+	}
+
+	if len(cfg.ExtraArgs) > 0 {
+		args = append(args, Arg{Mapping: Mapping{Field: "ExtraArgs"}, Args: cfg.ExtraArgs})
+	}
+	return args
+}
+
+// Build returns the geth command line arguments, without the binary itself,
+// for cfg. Fields left empty are omitted so geth applies its own defaults.
+func Build(cfg config.UserInputForNodeConfig) []string {
+	var args []string
+	for _, a := range Annotate(cfg) {
+		args = append(args, a.Args...)
+	}
+	return args
+}
+
+// DropUnsupported clears the fields of cfg whose flag supports rejects, so
+// geth does not refuse to start on a flag it does not define, and returns
+// the result with the mappings it cleared in command line order. Fields
+// left empty are not reported. ExtraArgs are kept as the user typed them.
+func DropUnsupported(cfg config.UserInputForNodeConfig, supports func(flag string) bool) (config.UserInputForNodeConfig, []Mapping) {
+	v := reflect.ValueOf(&cfg).Elem()
+
+	var dropped []Mapping
+	for _, m := range Mappings {
+		f := v.FieldByName(m.Field)
+		if f.IsZero() || f.Kind() == reflect.Slice && f.Len() == 0 || supports(m.Flag) {
+			continue
+		}
+		f.Set(reflect.Zero(f.Type()))
+		dropped = append(dropped, m)
+	}
+	return cfg, dropped
+}
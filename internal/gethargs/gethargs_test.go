@@ -0,0 +1,244 @@
+package gethargs
+
+import (
+	"reflect"
+	"testing"
+
+	"gene/internal/config"
+)
+
+// full sets every field that has a Mapping.
+var full = config.UserInputForNodeConfig{
+	TOMLConfig: "/etc/geth/config.toml",
+
+	HTTPEnabled:               true,
+	HTTPAddr:                  "0.0.0.0",
+	RPCHTTPPort:               "8545",
+	RPCHTTPSelectedAPIMethods: []string{"eth", "net", "web3"},
+	HTTPCorsDomain:            "https://example.org",
+	HTTPVirtualHosts:          "localhost,example.org",
+
+	WSEnabled:      true,
+	WSRPCInterface: "127.0.0.1",
+	WSRPCHTTPPort:  "8546",
+	WSRPCOrigins:   "*",
+	WSRPCAPIs:      []string{"eth"},
+
+	GraphQLEnabled:      true,
+	GraphQLCors:         "*",
+	GraphQLVirtualHosts: "localhost",
+
+	AdminAddr: "localhost",
+	AdminPort: "8551",
+
+	PreloadJS: "a.js,b.js",
+	ExecJS:    "eth.blockNumber",
+
+	DBEndpoint:    "http://db:8080",
+	TxLookupLimit: "0",
+	SyncMode:      "full",
+	NetworkID:     "1337",
+	P2PPort:       "30304",
+	DataDir:       "/home/user/my chain",
+	IPCDisable:    true,
+	IPCPath:       "geth.ipc",
+
+	UserAddress:               "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	MinerThreads:              "2",
+	NotifyURLs:                "http://pool:8000",
+	MinerMinimumGasPrice:      "1000000000",
+	MinerGasTarget:            "30000000",
+	MinerGasLimit:             "36000000",
+	MinerExtraData:            "0x676574682d676e",
+	MinerRecommit:             "3s",
+	MinerDisableRemoteSealing: true,
+
+	DeveloperMode:     true,
+	DeveloperPeriod:   "5",
+	DeveloperGasLimit: "11500000",
+}
+
+func TestFullCoversMappings(t *testing.T) {
+	v := reflect.ValueOf(full)
+	for _, m := range Mappings {
+		if v.FieldByName(m.Field).IsZero() {
+			t.Errorf("field %s is not set in the golden config", m.Field)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	withExtra := full
+	withExtra.ExtraArgs = []string{"--verbosity", "4", "--nat=none", "--nodiscover"}
+	withExtra.Env = []config.EnvVar{{Name: "GOGC", Value: "50"}}
+	withExtra.Binary = "geth-1.10"
+	withExtra.RestartPolicy = "always"
+	withExtra.ReadyTimeout = "2m"
+
+	golden := []string{
+		"--config", "/etc/geth/config.toml",
+		"--http",
+		"--http.addr", "0.0.0.0",
+		"--http.port", "8545",
+		"--http.api", "eth,net,web3",
+		"--http.corsdomain", "https://example.org",
+		"--http.vhosts", "localhost,example.org",
+		"--ws",
+		"--ws.addr", "127.0.0.1",
+		"--ws.port", "8546",
+		"--ws.origins", "*",
+		"--ws.api", "eth",
+		"--graphql",
+		"--graphql.corsdomain", "*",
+		"--graphql.vhosts", "localhost",
+		"--authrpc.addr", "localhost",
+		"--authrpc.port", "8551",
+		"--preload", "a.js,b.js",
+		"--exec", "eth.blockNumber",
+		"--db.endpoint", "http://db:8080",
+		"--txlookuplimit", "0",
+		"--syncmode", "full",
+		"--networkid", "1337",
+		"--port", "30304",
+		"--datadir", "/home/user/my chain",
+		"--ipcdisable",
+		"--ipcpath", "geth.ipc",
+		"--miner.etherbase", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"--miner.threads", "2",
+		"--miner.notify", "http://pool:8000",
+		"--miner.gasprice", "1000000000",
+		"--miner.gastarget", "30000000",
+		"--miner.gaslimit", "36000000",
+		"--miner.extradata", "0x676574682d676e",
+		"--miner.recommit", "3s",
+		"--miner.noverify",
+		"--dev",
+		"--dev.period", "5",
+		"--dev.gaslimit", "11500000",
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.UserInputForNodeConfig
+		want []string
+	}{
+		{
+			name: "empty",
+			cfg:  config.UserInputForNodeConfig{},
+			want: nil,
+		},
+		{
+			name: "every field",
+			cfg:  full,
+			want: golden,
+		},
+		{
+			name: "extra args last and in order, GeNe-only fields ignored",
+			cfg:  withExtra,
+			want: append(append([]string(nil), golden...), "--verbosity", "4", "--nat=none", "--nodiscover"),
+		},
+		{
+			name: "bool flags",
+			cfg: config.UserInputForNodeConfig{
+				HTTPEnabled:               false,
+				WSEnabled:                 true,
+				GraphQLEnabled:            false,
+				IPCDisable:                true,
+				MinerDisableRemoteSealing: false,
+				DeveloperMode:             true,
+			},
+			want: []string{"--ws", "--ipcdisable", "--dev"},
+		},
+		{
+			name: "empty lists are omitted",
+			cfg: config.UserInputForNodeConfig{
+				RPCHTTPSelectedAPIMethods: []string{},
+				WSRPCAPIs:                 []string{"eth", "debug"},
+			},
+			want: []string{"--ws.api", "eth,debug"},
+		},
+		{
+			name: "extra args only",
+			cfg:  config.UserInputForNodeConfig{ExtraArgs: []string{"--goerli", "--cache", "2048"}},
+			want: []string{"--goerli", "--cache", "2048"},
+		},
+	}
+	for _, tt := range tests {
+		if got := Build(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	cfg := config.UserInputForNodeConfig{
+		HTTPEnabled: true,
+		RPCHTTPPort: "8545",
+		ExtraArgs:   []string{"--nodiscover"},
+	}
+	want := []Arg{
+		{Mapping: Mapping{Field: "HTTPEnabled", Flag: "http"}, Args: []string{"--http"}},
+		{Mapping: Mapping{Field: "RPCHTTPPort", Flag: "http.port"}, Args: []string{"--http.port", "8545"}},
+		{Mapping: Mapping{Field: "ExtraArgs"}, Args: []string{"--nodiscover"}},
+	}
+	if got := Annotate(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Annotate = %v, want %v", got, want)
+	}
+}
+
+func TestCheckMappings(t *testing.T) {
+	if err := checkMappings(); err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, m := range Mappings {
+		if seen[m.Flag] {
+			t.Errorf("flag --%s is mapped twice", m.Flag)
+		}
+		seen[m.Flag] = true
+		if flag, ok := FlagFor(m.Field); !ok || flag != m.Flag {
+			t.Errorf("FlagFor(%s) = %q, %v, want %q", m.Field, flag, ok, m.Flag)
+		}
+	}
+	if _, ok := FlagFor("ExtraArgs"); ok {
+		t.Error("FlagFor(ExtraArgs) found a flag")
+	}
+}
+
+func TestDropUnsupported(t *testing.T) {
+	cfg := config.UserInputForNodeConfig{
+		HTTPEnabled:               true,
+		RPCHTTPPort:               "8545",
+		RPCHTTPSelectedAPIMethods: []string{},
+		MinerGasTarget:            "30000000",
+		MinerGasLimit:             "36000000",
+		TxLookupLimit:             "0",
+		ExtraArgs:                 []string{"--miner.gastarget", "1"},
+		Binary:                    "geth-1.14",
+	}
+	supported := map[string]bool{"http": true, "http.port": true}
+	got, dropped := DropUnsupported(cfg, func(flag string) bool { return supported[flag] })
+
+	want := config.UserInputForNodeConfig{
+		HTTPEnabled:               true,
+		RPCHTTPPort:               "8545",
+		RPCHTTPSelectedAPIMethods: []string{},
+		ExtraArgs:                 []string{"--miner.gastarget", "1"},
+		Binary:                    "geth-1.14",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DropUnsupported\n got %+v\nwant %+v", got, want)
+	}
+	wantDropped := []Mapping{
+		{Field: "TxLookupLimit", Flag: "txlookuplimit"},
+		{Field: "MinerGasTarget", Flag: "miner.gastarget"},
+		{Field: "MinerGasLimit", Flag: "miner.gaslimit"},
+	}
+	if !reflect.DeepEqual(dropped, wantDropped) {
+		t.Errorf("dropped = %v, want %v", dropped, wantDropped)
+	}
+
+	if got, dropped := DropUnsupported(full, func(string) bool { return true }); !reflect.DeepEqual(got, full) || dropped != nil {
+		t.Errorf("DropUnsupported with every flag supported changed the config or dropped %v", dropped)
+	}
+}
@@ -0,0 +1,241 @@
+package gethargs
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"gene/internal/config"
+)
+
+// legacyFlags maps flag names from older geth releases to the names used in Mappings.
+var legacyFlags = map[string]string{
+	"rpc":            "http",
+	"rpcaddr":        "http.addr",
+	"rpcport":        "http.port",
+	"rpcapi":         "http.api",
+	"rpccorsdomain":  "http.corsdomain",
+	"rpcvhosts":      "http.vhosts",
+	"wsaddr":         "ws.addr",
+	"wsport":         "ws.port",
+	"wsapi":          "ws.api",
+	"wsorigins":      "ws.origins",
+	"etherbase":      "miner.etherbase",
+	"minerthreads":   "miner.threads",
+	"gasprice":       "miner.gasprice",
+	"extradata":      "miner.extradata",
+	"targetgaslimit": "miner.gastarget",
+}
+
+// Split tokenizes a shell command line the way a POSIX shell would,
+// honouring single and double quotes, backslash escapes, line continuations
+// and comments. Variable expansion is not performed.
+func Split(cmdline string) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	flush := func() {
+		if inWord {
+			args = append(args, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(cmdline)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			escaped = false
+			// a backslash before a newline continues the line
+			if r != '\n' {
+				word.WriteRune(r)
+				inWord = true
+			}
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]):
+				i++
+				if runes[i] != '\n' {
+					word.WriteRune(runes[i])
+				}
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '#' && !inWord:
+			// skip the comment up to the end of the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	flush()
+	return args, nil
+}
+
+// Parsed is the result of ParseCommandLine.
+type Parsed struct {
+	// Config holds the settings of the command line
+	Config config.UserInputForNodeConfig
+	// Binary is the program the command line runs, empty if it started with a flag
+	Binary string
+	// Recognised lists the recognised flags, without dashes, in the order they appeared
+	Recognised []string
+}
+
+// GeNeFields lists the config.UserInputForNodeConfig fields that only GeNe
+// uses. A geth command line cannot set them, so ParseCommandLine keeps
+// their value from the base config.
+var GeNeFields = []string{
+	"Binary",
+	"RestartPolicy",
+	"MaxRestarts",
+	"RestartWindow",
+	"ReadyTimeout",
+}
+
+// ParseCommandLine maps a geth command line, such as one copied from a
+// script or systemd unit, back onto a config. It is the reverse of Build:
+// every setting comes from the command line, except for the GeNeFields,
+// which are taken from base. Flags that no Mapping covers are kept, in
+// order, in ExtraArgs rather than being dropped, and NAME=value assignments
+// before the binary, also those given to env, make up Env.
+func ParseCommandLine(cmdline string, base config.UserInputForNodeConfig) (Parsed, error) {
+	words, err := Split(cmdline)
+	if err != nil {
+		return Parsed{}, err
+	}
+	var p Parsed
+	src, dst := reflect.ValueOf(base), reflect.ValueOf(&p.Config).Elem()
+	for _, name := range GeNeFields {
+		dst.FieldByName(name).Set(src.FieldByName(name))
+	}
+
+	// environment assignments, exec and env, then the binary itself
+	for len(words) > 0 {
+		if isAssignment(words[0]) {
+			eq := strings.IndexByte(words[0], '=')
+			p.Config.Env = append(p.Config.Env, config.EnvVar{Name: words[0][:eq], Value: words[0][eq+1:]})
+		} else if words[0] != "exec" && words[0] != "env" {
+			break
+		}
+		words = words[1:]
+	}
+	if len(words) > 0 && !strings.HasPrefix(words[0], "-") {
+		p.Binary = words[0]
+		words = words[1:]
+	}
+
+	byFlag := make(map[string]Mapping, len(Mappings))
+	for _, m := range Mappings {
+		byFlag[m.Flag] = m
+	}
+
+	v := reflect.ValueOf(&p.Config).Elem()
+	var extra []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") || word == "-" || word == "--" {
+			extra = append(extra, word)
+			continue
+		}
+
+		name := strings.TrimLeft(word, "-")
+		value, hasValue := "", false
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name, value, hasValue = name[:eq], name[eq+1:], true
+		}
+		if alias, ok := legacyFlags[name]; ok {
+			name = alias
+		}
+
+		m, known := byFlag[name]
+		if !known {
+			// keep unknown flags together with what looks like their value
+			extra = append(extra, word)
+			if !hasValue && i+1 < len(words) && !strings.HasPrefix(words[i+1], "-") {
+				i++
+				extra = append(extra, words[i])
+			}
+			continue
+		}
+
+		f := v.FieldByName(m.Field)
+		if f.Kind() == reflect.Bool {
+			f.SetBool(!hasValue || value == "true" || value == "1")
+			p.Recognised = append(p.Recognised, name)
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(words) {
+				return p, errors.New("flag --" + name + " needs a value")
+			}
+			i++
+			value = words[i]
+		}
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(value)
+		case reflect.Slice:
+			var list []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			f.Set(reflect.ValueOf(list))
+		}
+		p.Recognised = append(p.Recognised, name)
+	}
+
+	p.Config.ExtraArgs = extra
+	return p, nil
+}
+
+// isAssignment reports whether word is a shell NAME=value assignment.
+func isAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range word[:eq] {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+package gethargs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gene/internal/config"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  \t\n ", nil},
+		{"geth --http", []string{"geth", "--http"}},
+		{"geth  --datadir\t/data   --http", []string{"geth", "--datadir", "/data", "--http"}},
+		{`geth --datadir '/home/user/my chain'`, []string{"geth", "--datadir", "/home/user/my chain"}},
+		{`geth --miner.extradata "say \"hi\" \$HOME \\ \x"`, []string{"geth", "--miner.extradata", `say "hi" $HOME \ \x`}},
+		{`geth --miner.extradata 'it'\''s'`, []string{"geth", "--miner.extradata", "it's"}},
+		{`geth --datadir my\ chain`, []string{"geth", "--datadir", "my chain"}},
+		{"geth \\\n  --http \\\n  --ws", []string{"geth", "--http", "--ws"}},
+		{"geth --ipcpath \"a\\\nb\"", []string{"geth", "--ipcpath", "ab"}},
+		{"# start geth\ngeth --http # with HTTP\n--ws", []string{"geth", "--http", "--ws"}},
+		{"geth --exec 'a#b' c#d", []string{"geth", "--exec", "a#b", "c#d"}},
+		{`geth --preload '' ""`, []string{"geth", "--preload", "", ""}},
+		{"geth --http\r\n", []string{"geth", "--http"}},
+		{`--miner.extradata=a"b c"d`, []string{"--miner.extradata=ab cd"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.in)
+		if err != nil {
+			t.Errorf("Split(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{`geth --datadir '/data`, `geth --exec "x`, `geth \`} {
+		if _, err := Split(in); err == nil {
+			t.Errorf("Split(%q) accepted an invalid command line", in)
+		}
+	}
+}
+
+func TestSplitQuoteCommand(t *testing.T) {
+	argv := []string{"geth", "", "plain", "two words", "it's", `"quoted"`, "$HOME", "back\\slash", "new\nline", "#hash", "tab\there"}
+	got, err := Split(QuoteCommand(argv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, argv) {
+		t.Errorf("Split(QuoteCommand(argv)) = %q, want %q", got, argv)
+	}
+}
+
+func TestParseCommandLine(t *testing.T) {
+	base := config.UserInputForNodeConfig{
+		// overwritten, or cleared, by the command line
+		HTTPEnabled:    true,
+		WSEnabled:      true,
+		RPCHTTPPort:    "9999",
+		DataDir:        "/old",
+		ExtraArgs:      []string{"--old"},
+		Env:            []config.EnvVar{{Name: "OLD", Value: "1"}},
+		MinerExtraData: "old",
+		// kept, a command line cannot set them
+		Binary:        "geth-1.10",
+		RestartPolicy: "always",
+		MaxRestarts:   "3",
+		RestartWindow: "1h",
+		ReadyTimeout:  "2m",
+	}
+	kept := func(cfg config.UserInputForNodeConfig) config.UserInputForNodeConfig {
+		cfg.Binary, cfg.RestartPolicy, cfg.MaxRestarts, cfg.RestartWindow, cfg.ReadyTimeout = "geth-1.10", "always", "3", "1h", "2m"
+		return cfg
+	}
+
+	tests := []struct {
+		name       string
+		cmdline    string
+		binary     string
+		recognised []string
+		want       config.UserInputForNodeConfig
+	}{
+		{
+			name:    "empty",
+			cmdline: "",
+			want:    kept(config.UserInputForNodeConfig{}),
+		},
+		{
+			name:       "flags",
+			cmdline:    "/usr/bin/geth --datadir /data --http --http.api eth,net,web3 --http.port=8545 --ipcdisable --syncmode full",
+			binary:     "/usr/bin/geth",
+			recognised: []string{"datadir", "http", "http.api", "http.port", "ipcdisable", "syncmode"},
+			want: kept(config.UserInputForNodeConfig{
+				DataDir:                   "/data",
+				HTTPEnabled:               true,
+				RPCHTTPSelectedAPIMethods: []string{"eth", "net", "web3"},
+				RPCHTTPPort:               "8545",
+				IPCDisable:                true,
+				SyncMode:                  "full",
+			}),
+		},
+		{
+			name:       "single dash, legacy names and bool values",
+			cmdline:    "geth -rpc -rpcport 8545 --wsapi 'eth, net,' --ws=false --ipcdisable=true -etherbase 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			binary:     "geth",
+			recognised: []string{"http", "http.port", "ws.api", "ws", "ipcdisable", "miner.etherbase"},
+			want: kept(config.UserInputForNodeConfig{
+				HTTPEnabled: true,
+				RPCHTTPPort: "8545",
+				WSRPCAPIs:   []string{"eth", "net"},
+				IPCDisable:  true,
+				UserAddress: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			}),
+		},
+		{
+			name:       "unknown flags are kept in order with their values",
+			cmdline:    "--goerli --cache 2048 --http --nat=extip:1.2.3.4 --verbosity 4 --nodiscover -- console",
+			recognised: []string{"http"},
+			want: kept(config.UserInputForNodeConfig{
+				HTTPEnabled: true,
+				ExtraArgs:   []string{"--goerli", "--cache", "2048", "--nat=extip:1.2.3.4", "--verbosity", "4", "--nodiscover", "--", "console"},
+			}),
+		},
+		{
+			name:       "environment",
+			cmdline:    "GOGC=50 GODEBUG='madvdontneed=1' exec geth --http",
+			binary:     "geth",
+			recognised: []string{"http"},
+			want: kept(config.UserInputForNodeConfig{
+				HTTPEnabled: true,
+				Env:         []config.EnvVar{{Name: "GOGC", Value: "50"}, {Name: "GODEBUG", Value: "madvdontneed=1"}},
+			}),
+		},
+		{
+			name:       "env",
+			cmdline:    "exec env FOO=bar BAZ= /opt/geth --http",
+			binary:     "/opt/geth",
+			recognised: []string{"http"},
+			want: kept(config.UserInputForNodeConfig{
+				HTTPEnabled: true,
+				Env:         []config.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "BAZ", Value: ""}},
+			}),
+		},
+		{
+			name:       "systemd unit",
+			cmdline:    "geth \\\n  --datadir=/var/lib/geth \\\n  --authrpc.jwtsecret /var/lib/geth/jwt.hex \\\n  --miner.extradata 'GeNe node'\n",
+			binary:     "geth",
+			recognised: []string{"datadir", "miner.extradata"},
+			want: kept(config.UserInputForNodeConfig{
+				DataDir:        "/var/lib/geth",
+				MinerExtraData: "GeNe node",
+				ExtraArgs:      []string{"--authrpc.jwtsecret", "/var/lib/geth/jwt.hex"},
+			}),
+		},
+	}
+	for _, tt := range tests {
+		p, err := ParseCommandLine(tt.cmdline, base)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if p.Binary != tt.binary {
+			t.Errorf("%s: Binary = %q, want %q", tt.name, p.Binary, tt.binary)
+		}
+		if !reflect.DeepEqual(p.Recognised, tt.recognised) {
+			t.Errorf("%s: Recognised = %q, want %q", tt.name, p.Recognised, tt.recognised)
+		}
+		if !reflect.DeepEqual(p.Config, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, p.Config, tt.want)
+		}
+	}
+
+	for _, cmdline := range []string{"geth --datadir", "geth --http.api", "geth 'unterminated"} {
+		if _, err := ParseCommandLine(cmdline, base); err == nil {
+			t.Errorf("ParseCommandLine(%q) accepted an invalid command line", cmdline)
+		}
+	}
+}
+
+func TestParseCommandLineReversesBuild(t *testing.T) {
+	withExtra := full
+	withExtra.ExtraArgs = []string{"--verbosity", "4", "--nat=none", "--nodiscover"}
+	withExtra.Env = []config.EnvVar{{Name: "GOGC", Value: "50"}, {Name: "EMPTY", Value: ""}}
+	withExtra.Binary = "geth-1.10"
+	withExtra.RestartPolicy = "on-failure"
+	withExtra.MaxRestarts = "5"
+	withExtra.RestartWindow = "10m"
+	withExtra.ReadyTimeout = "90s"
+	withExtra.MinerExtraData = "it's \"GeNe\""
+
+	for _, cfg := range []config.UserInputForNodeConfig{
+		{},
+		{HTTPEnabled: true, WSEnabled: true},
+		full,
+		withExtra,
+	} {
+		var words []string
+		for _, v := range cfg.Env {
+			words = append(words, QuoteAssignment(v.Name, v.Value))
+		}
+		words = append(words, QuoteCommand(append([]string{"geth"}, Build(cfg)...)))
+		cmdline := strings.Join(words, " ")
+
+		// the form holds other values, which the import must not keep
+		base := full
+		base.ExtraArgs = []string{"--old"}
+		base.Binary, base.RestartPolicy, base.MaxRestarts, base.RestartWindow, base.ReadyTimeout =
+			cfg.Binary, cfg.RestartPolicy, cfg.MaxRestarts, cfg.RestartWindow, cfg.ReadyTimeout
+
+		p, err := ParseCommandLine(cmdline, base)
+		if err != nil {
+			t.Errorf("ParseCommandLine(%q): %v", cmdline, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Config, cfg) {
+			t.Errorf("ParseCommandLine(%q)\n got %+v\nwant %+v", cmdline, p.Config, cfg)
+		}
+	}
+}
+
+func TestFormatArgLines(t *testing.T) {
+	args := []string{"--verbosity", "4", "--nat=none", "--nodiscover", "--bootnodes", "enode://a@1.2.3.4:30303 x"}
+	text := FormatArgLines(args)
+	want := "--verbosity 4\n--nat=none\n--nodiscover\n--bootnodes 'enode://a@1.2.3.4:30303 x'"
+	if text != want {
+		t.Errorf("FormatArgLines = %q, want %q", text, want)
+	}
+	if got, err := Split(text); err != nil || !reflect.DeepEqual(got, args) {
+		t.Errorf("Split(FormatArgLines(args)) = %q, %v, want %q", got, err, args)
+	}
+}
+
+func TestValidateExtraArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"--verbosity", "4", "--nat=none", "--nodiscover"}, 0},
+		{[]string{"console"}, 1},
+		{[]string{"--nodiscover=true", "console"}, 1},
+		{[]string{"--http.port", "8545"}, 1},
+		{[]string{"--rpcport=8545"}, 1},
+		{[]string{"--", "-"}, 2},
+	}
+	for _, tt := range tests {
+		errs := ValidateExtraArgs(config.UserInputForNodeConfig{ExtraArgs: tt.args})
+		if len(errs) != tt.want {
+			t.Errorf("ValidateExtraArgs(%q) = %v, want %d errors", tt.args, errs, tt.want)
+		}
+		for _, e := range errs {
+			if e.Field != "ExtraArgs" {
+				t.Errorf("ValidateExtraArgs(%q) reported field %s", tt.args, e.Field)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package gethargs
+
+import "strings"
+
+// shellSafe are the characters that never need quoting in a POSIX shell word.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%"
+
+// Quote returns s quoted for a POSIX shell. Words made only of safe
+// characters are returned unchanged, anything else is single quoted.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.Trim(s, shellSafe) == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuoteCommand joins argv into a single shell command line.
+func QuoteCommand(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		quoted[i] = Quote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// QuoteAssignment returns a NAME=value shell assignment. Only the value is
+// quoted, a quoted name would turn the assignment into a command word.
+func QuoteAssignment(name, value string) string {
+	return name + "=" + Quote(value)
+}
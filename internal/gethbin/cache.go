@@ -0,0 +1,88 @@
+package gethbin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Hash returns the hex encoded SHA-256 of the file at path.
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HelpCache keeps the parsed `geth --help` of every binary GeNe has seen,
+// one JSON file per binary hash, so the help is only run once per release.
+type HelpCache struct {
+	Dir string
+}
+
+// DefaultHelpCache returns a cache in the user's cache directory.
+func DefaultHelpCache() (*HelpCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &HelpCache{Dir: filepath.Join(dir, "gene", "help")}, nil
+}
+
+// Load returns the flags supported by the binary at path, running and
+// parsing `geth --help` only when the binary is not in the cache yet.
+func (c *HelpCache) Load(path string) (*Help, error) {
+	hash, err := Hash(path)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(c.Dir, hash+".json")
+
+	data, err := os.ReadFile(file)
+	if err == nil {
+		var h Help
+		if err := json.Unmarshal(data, &h); err == nil {
+			return &h, nil
+		}
+		// a corrupt entry is simply regenerated
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	out, err := ReadHelp(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := ParseHelp(out)
+	if err != nil {
+		return nil, err
+	}
+	return h, c.save(file, h)
+}
+
+func (c *HelpCache) save(file string, h *Help) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,113 @@
+package gethbin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Removal records a geth flag, or an RPC API module, that stopped working
+// in some release.
+type Removal struct {
+	// Flag is the flag name without dashes; empty for an API module
+	Flag string
+	// API is the RPC API module name, e.g. "shh"; empty for a flag
+	API string
+	// Removed is the first release that no longer supports it
+	Removed Version
+	// Hint tells the user what to do instead
+	Hint string
+}
+
+// Removals lists the flags and API modules GeNe knows were removed from geth.
+var Removals = []Removal{
+	{Flag: "miner.gastarget", Removed: V(1, 10, 5), Hint: "since London the gas target is half the gas limit; set --miner.gaslimit instead"},
+	{Flag: "txlookuplimit", Removed: V(1, 13, 12), Hint: "use --history.transactions"},
+	{Flag: "rpc", Removed: V(1, 10, 0), Hint: "use --http"},
+	{Flag: "rpcaddr", Removed: V(1, 10, 0), Hint: "use --http.addr"},
+	{Flag: "rpcport", Removed: V(1, 10, 0), Hint: "use --http.port"},
+	{Flag: "rpcapi", Removed: V(1, 10, 0), Hint: "use --http.api"},
+	{Flag: "rpccorsdomain", Removed: V(1, 10, 0), Hint: "use --http.corsdomain"},
+	{Flag: "rpcvhosts", Removed: V(1, 10, 0), Hint: "use --http.vhosts"},
+	{Flag: "wsaddr", Removed: V(1, 10, 0), Hint: "use --ws.addr"},
+	{Flag: "wsport", Removed: V(1, 10, 0), Hint: "use --ws.port"},
+	{Flag: "wsapi", Removed: V(1, 10, 0), Hint: "use --ws.api"},
+	{Flag: "wsorigins", Removed: V(1, 10, 0), Hint: "use --ws.origins"},
+	{Flag: "graphql.addr", Removed: V(1, 9, 16), Hint: "GraphQL is served on the HTTP-RPC address"},
+	{Flag: "graphql.port", Removed: V(1, 9, 16), Hint: "GraphQL is served on the HTTP-RPC port"},
+	{API: "shh", Removed: V(1, 9, 20), Hint: "Whisper was removed from geth"},
+	{API: "les", Removed: V(1, 14, 0), Hint: "the light client protocol was removed from geth"},
+}
+
+// apiFlags are the flags taking a comma separated list of API modules.
+var apiFlags = map[string]bool{"http.api": true, "ws.api": true, "rpcapi": true, "wsapi": true}
+
+// Check returns a warning for every flag or API module in args that v no
+// longer supports. Nothing is reported when the version is unknown.
+func Check(args []string, v Version) []string {
+	if v.IsZero() {
+		return nil
+	}
+	var warnings []string
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			value = args[i+1]
+		}
+
+		for _, r := range Removals {
+			if !v.AtLeast(r.Removed) {
+				continue
+			}
+			if r.Flag == name {
+				warnings = append(warnings, fmt.Sprintf("--%s is not supported since geth %s: %s", name, r.Removed, r.Hint))
+			}
+			if r.API != "" && apiFlags[name] && containsAPI(value, r.API) {
+				warnings = append(warnings, fmt.Sprintf("the %s API in --%s is not supported since geth %s: %s", r.API, name, r.Removed, r.Hint))
+			}
+		}
+	}
+	return warnings
+}
+
+func containsAPI(list, api string) bool {
+	for _, a := range strings.Split(list, ",") {
+		if strings.TrimSpace(a) == api {
+			return true
+		}
+	}
+	return false
+}
+
+// APIModules are the RPC API modules GeNe offers for --http.api and --ws.api.
+var APIModules = []string{"eth", "net", "web3", "txpool", "debug", "admin", "miner", "shh", "clique", "les"}
+
+// SupportedAPIs returns the APIModules that v still supports, or all of
+// them when the version is unknown.
+func SupportedAPIs(v Version) []string {
+	var apis []string
+	for _, api := range APIModules {
+		supported := true
+		for _, r := range Removals {
+			if r.API == api && !v.IsZero() && v.AtLeast(r.Removed) {
+				supported = false
+			}
+		}
+		if supported {
+			apis = append(apis, api)
+		}
+	}
+	return apis
+}
+
+// AvailableAPIs returns the API modules the help of a binary lists for
+// --http.api, falling back to the SupportedAPIs of v when the help is not
+// loaded or does not name any.
+func AvailableAPIs(h *Help, v Version) []string {
+	if apis := h.APIs(); len(apis) > 0 {
+		return apis
+	}
+	return SupportedAPIs(v)
+}
@@ -0,0 +1,120 @@
+// Package gethbin finds the geth binary GeNe runs and probes its version.
+package gethbin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Name is the file name geth is searched under.
+const Name = "geth"
+
+// ProbeTimeout bounds how long `geth version` may take.
+const ProbeTimeout = 10 * time.Second
+
+// ErrNotFound is returned by Locate when no geth binary could be found.
+var ErrNotFound = errors.New("geth binary not found")
+
+// Binary is a geth executable and the version it reported.
+type Binary struct {
+	// Path is the absolute path of the executable
+	Path string
+	// Version is the output of `geth version`, zero if it could not be probed
+	Version Version
+}
+
+func (b Binary) String() string {
+	if b.Version.IsZero() {
+		return b.Path
+	}
+	return fmt.Sprintf("%s (%s)", b.Path, b.Version)
+}
+
+// Candidates returns the paths Locate tries, in order: the configured path,
+// if any, then geth on $PATH and geth next to the GeNe executable.
+func Candidates(configured string) []string {
+	var candidates []string
+	if configured != "" {
+		candidates = append(candidates, configured)
+	}
+	if p, err := exec.LookPath(Name); err == nil {
+		candidates = append(candidates, p)
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), Name))
+	}
+	return candidates
+}
+
+// Locate returns the absolute path of the first executable candidate. A
+// configured path without a directory, e.g. "geth-1.10", is looked up on
+// $PATH like a shell would.
+func Locate(configured string) (string, error) {
+	candidates := Candidates(configured)
+	for _, c := range candidates {
+		if p, err := Executable(c); err == nil {
+			return p, nil
+		}
+	}
+	if len(candidates) == 0 {
+		return "", ErrNotFound
+	}
+	return "", fmt.Errorf("%w, tried %s", ErrNotFound, strings.Join(candidates, ", "))
+}
+
+// Executable returns the absolute path of the executable at path, never
+// another geth. A path without a directory, e.g. "geth-1.10", is looked up
+// on $PATH like a shell would.
+func Executable(path string) (string, error) {
+	if !strings.ContainsRune(path, filepath.Separator) {
+		p, err := exec.LookPath(path)
+		if err != nil {
+			return "", err
+		}
+		path = p
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	if !isExecutable(path) {
+		return "", fmt.Errorf("%s is not an executable file", path)
+	}
+	return filepath.Abs(path)
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular() && fi.Mode().Perm()&0111 != 0
+}
+
+// Probe runs `geth version` and parses its output.
+func Probe(path string) (Version, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ProbeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	if ctx.Err() != nil {
+		return Version{}, fmt.Errorf("%s version: %w", path, ctx.Err())
+	}
+	if err != nil {
+		return Version{}, fmt.Errorf("%s version: %w", path, err)
+	}
+	return ParseVersion(string(out))
+}
+
+// Detect locates geth and probes its version. The binary is returned even
+// when only the probe failed, so it can still be launched.
+func Detect(configured string) (Binary, error) {
+	path, err := Locate(configured)
+	if err != nil {
+		return Binary{}, err
+	}
+	v, err := Probe(path)
+	return Binary{Path: path, Version: v}, err
+}
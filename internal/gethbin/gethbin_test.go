@@ -0,0 +1,274 @@
+package gethbin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out  string
+		want Version
+	}{
+		{
+			out: `Geth
+Version: 1.10.26-stable
+Git Commit: e5eb32acee19cc9fca6a03b10283b7484246b15a
+Git Commit Date: 20221103
+Architecture: amd64
+Go Version: go1.18.5
+Operating System: linux
+GOPATH=
+GOROOT=go
+`,
+			want: Version{Major: 1, Minor: 10, Patch: 26, Meta: "stable", Commit: "e5eb32acee19cc9fca6a03b10283b7484246b15a"},
+		},
+		{
+			// the commit line may come first, and Windows ends lines with \r\n
+			out:  "Geth\r\nGit Commit: 0123abcd\r\nVersion: 1.13.15-unstable\r\n",
+			want: Version{Major: 1, Minor: 13, Patch: 15, Meta: "unstable", Commit: "0123abcd"},
+		},
+		{
+			out:  "Version: 1.9.0",
+			want: Version{Major: 1, Minor: 9, Patch: 0},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.out)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, %v, want %+v", tt.out, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseVersion("Geth\nArchitecture: amd64\n"); !errors.Is(err, ErrNoVersion) {
+		t.Errorf("ParseVersion without a version: error = %v, want ErrNoVersion", err)
+	}
+	for _, out := range []string{"Version: 1.10", "Version: 1.x.0-stable", "Version: 1.10.-1", "Version: "} {
+		if _, err := ParseVersion(out); err == nil || errors.Is(err, ErrNoVersion) {
+			t.Errorf("ParseVersion(%q) error = %v, want an invalid version error", out, err)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		v, w Version
+		cmp  int
+	}{
+		{V(1, 10, 26), V(1, 10, 26), 0},
+		{Version{Major: 1, Minor: 10, Patch: 26, Meta: "stable"}, V(1, 10, 26), 0},
+		{V(1, 10, 4), V(1, 10, 5), -1},
+		{V(1, 9, 25), V(1, 10, 0), -1},
+		{V(2, 0, 0), V(1, 14, 9), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Compare(tt.w); got != tt.cmp {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.v, tt.w, got, tt.cmp)
+		}
+		if got := tt.v.AtLeast(tt.w); got != (tt.cmp >= 0) {
+			t.Errorf("%s.AtLeast(%s) = %v", tt.v, tt.w, got)
+		}
+	}
+	if s := (Version{Major: 1, Minor: 10, Patch: 26, Meta: "stable"}).String(); s != "1.10.26-stable" {
+		t.Errorf("String() = %q", s)
+	}
+	if !(Version{}).IsZero() || V(0, 0, 1).IsZero() {
+		t.Error("IsZero is wrong")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	args := []string{
+		"--http", "--http.api", "eth,shh,les", "--miner.gastarget", "30000000",
+		"--txlookuplimit=0", "-rpcport", "8545", "--ws.api=eth, les", "--graphql.port", "8547",
+	}
+	tests := []struct {
+		version Version
+		want    []string
+	}{
+		// an unknown version is never reported
+		{Version{}, nil},
+		{V(1, 9, 15), nil},
+		{V(1, 9, 16), []string{
+			"--graphql.port is not supported since geth 1.9.16: GraphQL is served on the HTTP-RPC port",
+		}},
+		{V(1, 9, 20), []string{
+			"the shh API in --http.api is not supported since geth 1.9.20: Whisper was removed from geth",
+			"--graphql.port is not supported since geth 1.9.16: GraphQL is served on the HTTP-RPC port",
+		}},
+		{V(1, 10, 0), []string{
+			"the shh API in --http.api is not supported since geth 1.9.20: Whisper was removed from geth",
+			"--rpcport is not supported since geth 1.10.0: use --http.port",
+			"--graphql.port is not supported since geth 1.9.16: GraphQL is served on the HTTP-RPC port",
+		}},
+		{V(1, 10, 5), []string{
+			"the shh API in --http.api is not supported since geth 1.9.20: Whisper was removed from geth",
+			"--miner.gastarget is not supported since geth 1.10.5: since London the gas target is half the gas limit; set --miner.gaslimit instead",
+			"--rpcport is not supported since geth 1.10.0: use --http.port",
+			"--graphql.port is not supported since geth 1.9.16: GraphQL is served on the HTTP-RPC port",
+		}},
+		{V(1, 14, 0), []string{
+			"the shh API in --http.api is not supported since geth 1.9.20: Whisper was removed from geth",
+			"the les API in --http.api is not supported since geth 1.14.0: the light client protocol was removed from geth",
+			"--miner.gastarget is not supported since geth 1.10.5: since London the gas target is half the gas limit; set --miner.gaslimit instead",
+			"--txlookuplimit is not supported since geth 1.13.12: use --history.transactions",
+			"--rpcport is not supported since geth 1.10.0: use --http.port",
+			"the les API in --ws.api is not supported since geth 1.14.0: the light client protocol was removed from geth",
+			"--graphql.port is not supported since geth 1.9.16: GraphQL is served on the HTTP-RPC port",
+		}},
+	}
+	for _, tt := range tests {
+		if got := Check(args, tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Check for %s:\n got %q\nwant %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestRemovals(t *testing.T) {
+	// every removal is reported by the first release without it and not by the one before
+	for _, r := range Removals {
+		if (r.Flag == "") == (r.API == "") {
+			t.Errorf("removal %+v must name either a flag or an API", r)
+			continue
+		}
+		if r.Hint == "" {
+			t.Errorf("removal %+v has no hint", r)
+		}
+		args := []string{"--" + r.Flag}
+		if r.API != "" {
+			args = []string{"--http.api", r.API}
+		}
+		if got := Check(args, r.Removed); len(got) != 1 {
+			t.Errorf("Check(%q, %s) = %q, want one warning", args, r.Removed, got)
+		}
+		before := r.Removed
+		switch {
+		case before.Patch > 0:
+			before.Patch--
+		case before.Minor > 0:
+			before.Minor, before.Patch = before.Minor-1, 99
+		}
+		if got := Check(args, before); len(got) != 0 {
+			t.Errorf("Check(%q, %s) = %q, want no warning", args, before, got)
+		}
+	}
+}
+
+func TestSupportedAPIs(t *testing.T) {
+	tests := []struct {
+		version Version
+		want    []string
+	}{
+		{Version{}, APIModules},
+		{V(1, 9, 19), APIModules},
+		{V(1, 10, 26), []string{"eth", "net", "web3", "txpool", "debug", "admin", "miner", "clique", "les"}},
+		{V(1, 14, 0), []string{"eth", "net", "web3", "txpool", "debug", "admin", "miner", "clique"}},
+	}
+	for _, tt := range tests {
+		if got := SupportedAPIs(tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SupportedAPIs(%s) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+// writeExecutable creates an executable file called name in dir.
+func writeExecutable(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLocate(t *testing.T) {
+	if os.PathSeparator != '/' {
+		t.Skip("uses POSIX executables")
+	}
+	dir := t.TempDir()
+	onPath := writeExecutable(t, dir, Name)
+	configured := writeExecutable(t, t.TempDir(), "geth-1.10")
+	t.Setenv("PATH", dir)
+
+	// a geth in the working directory is not picked up
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cwd := t.TempDir()
+	writeExecutable(t, cwd, Name)
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	candidates := Candidates(configured)
+	if len(candidates) < 2 || candidates[0] != configured || candidates[1] != onPath {
+		t.Errorf("Candidates(%s) = %q, want the configured path, then $PATH", configured, candidates)
+	}
+	for _, c := range Candidates("") {
+		if strings.HasPrefix(c, "."+string(filepath.Separator)) || c == filepath.Join(cwd, Name) {
+			t.Errorf("Candidates(\"\") = %q includes the working directory", Candidates(""))
+		}
+	}
+
+	tests := []struct {
+		configured string
+		want       string
+	}{
+		{"", onPath},
+		{configured, configured},
+		// a configured name is looked up on $PATH
+		{Name, onPath},
+		// a configured path that does not exist falls back to $PATH
+		{filepath.Join(dir, "missing"), onPath},
+		// so does one that is not executable
+		{filepath.Join(cwd, "."), onPath},
+	}
+	for _, tt := range tests {
+		if got, err := Locate(tt.configured); err != nil || got != tt.want {
+			t.Errorf("Locate(%q) = %q, %v, want %q", tt.configured, got, err, tt.want)
+		}
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := Locate(filepath.Join(dir, "missing")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Locate without any geth: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHelpAPIs(t *testing.T) {
+	tests := []struct {
+		flag Flag
+		want []string
+	}{
+		{Flag{Name: "http.api", Usage: "API's offered over the HTTP-RPC interface"}, nil},
+		{Flag{Name: "http.api", Default: "eth,net,web3", Usage: "API's offered over the HTTP-RPC interface"}, []string{"eth", "net", "web3"}},
+		{Flag{Name: "http.api", Default: "eth", Usage: "API's offered over the HTTP-RPC interface"}, []string{"eth"}},
+		{Flag{Name: "http.api", Usage: "API's offered over the HTTP-RPC interface, e.g. (eth,net,web3,txpool)."}, []string{"eth", "net", "web3", "txpool"}},
+		{Flag{Name: "http.api", Default: "eth,net", Usage: "one of eth,debug or admin,net"}, []string{"eth", "net", "debug", "admin"}},
+		{Flag{Name: "rpcapi", Default: "eth,net,web3,shh"}, []string{"eth", "net", "web3", "shh"}},
+		{Flag{Name: "http.api", Usage: "Comma separated list, e.g. Eth,NET or 8545,8546"}, nil},
+	}
+	for _, tt := range tests {
+		h := &Help{Flags: []Flag{{Name: "http"}, tt.flag}}
+		if got := h.APIs(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("APIs for %+v = %q, want %q", tt.flag, got, tt.want)
+		}
+	}
+	if got := (&Help{Flags: []Flag{{Name: "ws.api", Default: "eth,net"}}}).APIs(); got != nil {
+		t.Errorf("APIs without --http.api = %q, want nil", got)
+	}
+
+	h := &Help{Flags: []Flag{{Name: "http.api", Default: "eth,debug"}}}
+	if got := AvailableAPIs(h, V(1, 14, 0)); !reflect.DeepEqual(got, []string{"eth", "debug"}) {
+		t.Errorf("AvailableAPIs with a help = %q", got)
+	}
+	if got := AvailableAPIs(nil, V(1, 14, 0)); !reflect.DeepEqual(got, SupportedAPIs(V(1, 14, 0))) {
+		t.Errorf("AvailableAPIs without a help = %q, want SupportedAPIs", got)
+	}
+}
@@ -0,0 +1,222 @@
+package gethbin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoFlags is returned by ParseHelp when the output lists no flags.
+var ErrNoFlags = errors.New("no flags in geth help output")
+
+// Flag is a command line flag as described by `geth --help`.
+type Flag struct {
+	// Name is the flag name without dashes
+	Name string
+	// Aliases are the other names of the flag, without dashes
+	Aliases []string `json:",omitempty"`
+	// Category is the help section the flag is listed under, e.g. "ETHEREUM"
+	Category string
+	// TakesValue is true for flags followed by a value rather than switches
+	TakesValue bool
+	// Default is the default value geth reports, without quotes
+	Default string `json:",omitempty"`
+	// Usage is the help text, without the default and environment variable
+	Usage string
+}
+
+// Help is the set of flags a geth binary supports.
+type Help struct {
+	Flags []Flag
+}
+
+// Lookup returns the flag called name, or one of its aliases.
+func (h *Help) Lookup(name string) (Flag, bool) {
+	if h == nil {
+		return Flag{}, false
+	}
+	for _, f := range h.Flags {
+		if f.Name == name {
+			return f, true
+		}
+		for _, a := range f.Aliases {
+			if a == name {
+				return f, true
+			}
+		}
+	}
+	return Flag{}, false
+}
+
+// Supports reports whether the binary accepts the flag called name.
+func (h *Help) Supports(name string) bool {
+	_, ok := h.Lookup(name)
+	return ok
+}
+
+// APIs returns the RPC API modules listed by the default or the usage of
+// --http.api, --rpcapi before geth 1.10, e.g. "eth,net,web3", or nil when
+// the help lists none.
+func (h *Help) APIs() []string {
+	f, ok := h.Lookup("http.api")
+	if !ok {
+		f, ok = h.Lookup("rpcapi")
+	}
+	if !ok {
+		return nil
+	}
+
+	var apis []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(f.Default + " " + f.Usage) {
+		// a module list in the usage has at least two modules, so a
+		// comma ending a word of the text is not taken for one
+		word = strings.Trim(word, `"'().,;:`)
+		if !strings.Contains(word, ",") && word != f.Default {
+			continue
+		}
+		modules := strings.Split(word, ",")
+		if !isModuleList(modules) {
+			continue
+		}
+		for _, m := range modules {
+			if m != "" && !seen[m] {
+				seen[m] = true
+				apis = append(apis, m)
+			}
+		}
+	}
+	return apis
+}
+
+// isModuleList reports whether every word is an API module name such as
+// "eth" or "web3": lowercase letters, then optionally digits.
+func isModuleList(words []string) bool {
+	for _, w := range words {
+		digits := strings.TrimLeft(w, "abcdefghijklmnopqrstuvwxyz")
+		if digits == w && w != "" || strings.Trim(digits, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadHelp runs `geth --help` and returns its output.
+func ReadHelp(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ProbeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, "--help")
+	// older releases print the help to stderr
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s --help: %w", path, ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s --help: %w", path, err)
+	}
+	return string(out), nil
+}
+
+// ParseHelp reads the flags out of `geth --help`. It understands both the
+// layout of geth 1.10, with one "<CATEGORY> OPTIONS:" section per category
+// and the usage on the flag line:
+//
+//	ETHEREUM OPTIONS:
+//	  --datadir value    Data directory for the databases and keystore (default: "/home/u/.ethereum")
+//
+// and the layout of later releases, with category headings inside a single
+// "GLOBAL OPTIONS:" section and the usage possibly on the following lines:
+//
+//	GLOBAL OPTIONS:
+//	   API AND CONSOLE
+//
+//	    --http.port value    (default: 8545) ($GETH_HTTP_PORT)
+//	          HTTP-RPC server listening port
+func ParseHelp(out string) (*Help, error) {
+	h := &Help{}
+	var section, category string
+	var last *Flag
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case line[0] != ' ' && line[0] != '\t' && strings.HasSuffix(line, ":"):
+			// top level section, e.g. "COMMANDS:" or "ETHEREUM OPTIONS:"
+			section = strings.TrimSuffix(line, ":")
+			category = strings.TrimSpace(strings.TrimSuffix(section, "OPTIONS"))
+			last = nil
+			continue
+		case !strings.Contains(section, "OPTIONS"):
+			continue
+		case strings.HasPrefix(trimmed, "-"):
+			h.Flags = append(h.Flags, parseFlagLine(trimmed, category))
+			last = &h.Flags[len(h.Flags)-1]
+		case trimmed == strings.ToUpper(trimmed) && !strings.ContainsAny(trimmed, "().,:"):
+			// category heading inside GLOBAL OPTIONS
+			category = trimmed
+			last = nil
+		case last != nil:
+			// usage continued on the next line
+			last.Usage = strings.TrimSpace(last.Usage + " " + trimmed)
+		}
+	}
+
+	if len(h.Flags) == 0 {
+		return nil, ErrNoFlags
+	}
+	for i := range h.Flags {
+		h.Flags[i].Usage, h.Flags[i].Default = splitDefault(h.Flags[i].Usage)
+	}
+	return h, nil
+}
+
+// parseFlagLine parses "--http.port value    HTTP-RPC server listening port"
+// or "--goerli, --görli    Görli network".
+func parseFlagLine(line, category string) Flag {
+	names, usage := line, ""
+	if i := strings.Index(line, "  "); i >= 0 {
+		names, usage = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	f := Flag{Category: category, Usage: usage}
+	for _, n := range strings.Split(names, ",") {
+		words := strings.Fields(n)
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) > 1 {
+			f.TakesValue = true
+		}
+		name := strings.TrimLeft(words[0], "-")
+		if f.Name == "" {
+			f.Name = name
+		} else {
+			f.Aliases = append(f.Aliases, name)
+		}
+	}
+	return f
+}
+
+// splitDefault removes "(default: x)" and "($GETH_X)" from usage and returns
+// the remaining text and the unquoted default.
+func splitDefault(usage string) (string, string) {
+	var def string
+	if i := strings.Index(usage, "(default: "); i >= 0 {
+		if j := strings.Index(usage[i:], ")"); j >= 0 {
+			def = strings.Trim(usage[i+len("(default: "):i+j], `"`)
+			usage = usage[:i] + usage[i+j+1:]
+		}
+	}
+	if i := strings.Index(usage, "($"); i >= 0 {
+		if j := strings.Index(usage[i:], ")"); j >= 0 {
+			usage = usage[:i] + usage[i+j+1:]
+		}
+	}
+	return strings.Join(strings.Fields(usage), " "), def
+}
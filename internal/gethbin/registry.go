@@ -0,0 +1,223 @@
+package gethbin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownBinary is returned when no registered binary has the requested label.
+	ErrUnknownBinary = errors.New("no geth binary registered under that label")
+	// ErrModified is returned when a registered binary no longer matches its recorded SHA-256.
+	ErrModified = errors.New("geth binary changed since it was registered")
+	// ErrChecksumMismatch is returned when a binary does not match its checksum file.
+	ErrChecksumMismatch = errors.New("geth binary does not match the checksum file")
+)
+
+// Entry is a geth binary registered under a label, e.g. "1.10.26".
+type Entry struct {
+	Label string
+	// Path is the absolute path of the executable
+	Path string
+	// Version is the version the binary reported when it was registered
+	Version Version
+	// SHA256 is the hex encoded hash of the binary when it was registered
+	SHA256 string
+	// ChecksumFile is the checksum file the binary was verified against, if any
+	ChecksumFile string `json:",omitempty"`
+}
+
+// Binary returns the entry as a launchable Binary.
+func (e Entry) Binary() Binary {
+	return Binary{Path: e.Path, Version: e.Version}
+}
+
+// Verify checks that the binary still has the hash it was registered with.
+func (e Entry) Verify() error {
+	hash, err := Hash(e.Path)
+	if err != nil {
+		return err
+	}
+	if hash != e.SHA256 {
+		return fmt.Errorf("%s: %w", e.Path, ErrModified)
+	}
+	return nil
+}
+
+// Registry keeps the geth binaries GeNe can run in a JSON file.
+type Registry struct {
+	// File is the JSON file holding the entries
+	File string
+}
+
+// DefaultRegistry returns the registry under the user configuration
+// directory, e.g. ~/.config/gene/binaries.json on Linux.
+func DefaultRegistry() (*Registry, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{File: filepath.Join(dir, "gene", "binaries.json")}, nil
+}
+
+// List returns the registered binaries sorted by label.
+func (r *Registry) List() ([]Entry, error) {
+	data, err := os.ReadFile(r.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.File, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Label < entries[j].Label })
+	return entries, nil
+}
+
+// Get returns the binary registered under label.
+func (r *Registry) Get(label string) (Entry, error) {
+	entries, err := r.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.Label == label {
+			return e, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%q: %w", label, ErrUnknownBinary)
+}
+
+// Resolve returns the binary registered under label after checking it has
+// not been replaced since it was registered.
+func (r *Registry) Resolve(label string) (Entry, error) {
+	e, err := r.Get(label)
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, e.Verify()
+}
+
+// Add hashes and probes the binary at path and registers it under label,
+// replacing any binary with the same label. When checksumFile is not empty
+// the binary is only registered if it matches the checksum listed there.
+func (r *Registry) Add(label, path, checksumFile string) (Entry, error) {
+	label = strings.TrimSpace(label)
+	switch {
+	case label == "":
+		return Entry{}, errors.New("binary label is empty")
+	case path == "":
+		return Entry{}, errors.New("binary path is empty")
+	}
+	// only the binary given is registered, Locate would fall back to another
+	abs, err := Executable(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	hash, err := Hash(abs)
+	if err != nil {
+		return Entry{}, err
+	}
+	if checksumFile != "" {
+		if err := VerifyChecksumFile(abs, hash, checksumFile); err != nil {
+			return Entry{}, err
+		}
+	}
+	v, err := Probe(abs)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{Label: label, Path: abs, Version: v, SHA256: hash, ChecksumFile: checksumFile}
+	entries, err := r.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	kept := entries[:0]
+	for _, old := range entries {
+		if old.Label != label {
+			kept = append(kept, old)
+		}
+	}
+	return e, r.save(append(kept, e))
+}
+
+// Remove unregisters the binary with label. The file itself is left alone.
+func (r *Registry) Remove(label string) error {
+	entries, err := r.List()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Label != label {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return fmt.Errorf("%q: %w", label, ErrUnknownBinary)
+	}
+	return r.save(kept)
+}
+
+func (r *Registry) save(entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.File), 0o700); err != nil {
+		return err
+	}
+	tmp := r.File + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.File)
+}
+
+// VerifyChecksumFile checks hash, the SHA-256 of the binary at path, against
+// a checksum file in the format written by sha256sum:
+//
+//	<hex>  geth
+//
+// The line naming the binary's file name is used; a file holding a single
+// checksum may omit the name.
+func VerifyChecksumFile(path, hash, checksumFile string) error {
+	data, err := os.ReadFile(checksumFile)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	var sums []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		sum := strings.ToLower(fields[0])
+		if len(fields) > 1 && filepath.Base(strings.TrimPrefix(fields[1], "*")) == name {
+			sums = []string{sum}
+			break
+		}
+		sums = append(sums, sum)
+	}
+
+	switch {
+	case len(sums) == 0:
+		return fmt.Errorf("%s: no checksum found", checksumFile)
+	case len(sums) > 1:
+		return fmt.Errorf("%s: no checksum for %s", checksumFile, name)
+	case sums[0] != hash:
+		return fmt.Errorf("%s: %w %s", path, ErrChecksumMismatch, checksumFile)
+	}
+	return nil
+}
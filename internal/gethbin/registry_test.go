@@ -0,0 +1,88 @@
+package gethbin
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeGeth creates a stand-in geth in dir that reports version.
+func writeGeth(t *testing.T, dir, name, version string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\necho 'Geth'\necho 'Version: " + version + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegistryAdd(t *testing.T) {
+	if os.PathSeparator != '/' {
+		t.Skip("uses POSIX executables")
+	}
+	// a geth on $PATH must never be registered in place of the path given
+	pathDir := t.TempDir()
+	writeGeth(t, pathDir, Name, "1.13.0-stable")
+	writeGeth(t, pathDir, "geth-1.11", "1.11.6-stable")
+	t.Setenv("PATH", pathDir)
+
+	dir := t.TempDir()
+	old := writeGeth(t, dir, "geth-1.10", "1.10.26-stable")
+	r := &Registry{File: filepath.Join(t.TempDir(), "binaries.json")}
+
+	e, err := r.Add(" 1.10 ", old, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Label != "1.10" || e.Path != old || e.Version.String() != "1.10.26-stable" || e.SHA256 == "" {
+		t.Errorf("Add = %+v", e)
+	}
+	if err := e.Verify(); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	// a bare name is looked up on $PATH
+	if e, err := r.Add("1.11", "geth-1.11", ""); err != nil || e.Path != filepath.Join(pathDir, "geth-1.11") {
+		t.Errorf("Add of a name on $PATH = %+v, %v", e, err)
+	}
+
+	for _, missing := range []string{
+		filepath.Join(dir, "does-not-exist", "geth"),
+		filepath.Join(".", "does-not-exist", "geth"),
+		"geth-does-not-exist",
+	} {
+		if e, err := r.Add("1.10", missing, ""); err == nil {
+			t.Errorf("Add(%q) registered %s", missing, e.Path)
+		}
+	}
+	if _, err := r.Add("1.10", filepath.Join(dir, "does-not-exist", "geth"), ""); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Add of a missing path: error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := r.Add("dir", dir, ""); err == nil {
+		t.Error("Add registered a directory")
+	}
+
+	// the failed additions left the registered binary alone
+	if e, err := r.Get("1.10"); err != nil || e.Path != old {
+		t.Errorf("Get(1.10) = %+v, %v, want %s", e, err, old)
+	}
+	entries, err := r.List()
+	if err != nil || len(entries) != 2 {
+		t.Errorf("List = %+v, %v, want 2 entries", entries, err)
+	}
+
+	// a binary replaced after registration no longer resolves
+	writeGeth(t, dir, "geth-1.10", "1.10.25-stable")
+	if _, err := r.Resolve("1.10"); !errors.Is(err, ErrModified) {
+		t.Errorf("Resolve of a replaced binary: error = %v, want ErrModified", err)
+	}
+	if err := r.Remove("1.10"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("1.10"); !errors.Is(err, ErrUnknownBinary) {
+		t.Errorf("Get after Remove: error = %v, want ErrUnknownBinary", err)
+	}
+}
@@ -0,0 +1,107 @@
+package gethbin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNoVersion is returned by ParseVersion when the output has no version line.
+var ErrNoVersion = errors.New("no version in geth output")
+
+// Version is a geth release version, e.g. 1.10.26-stable.
+type Version struct {
+	Major, Minor, Patch int
+	// Meta is the release suffix, e.g. "stable" or "unstable"
+	Meta string
+	// Commit is the git commit geth was built from, if it reported one
+	Commit string
+}
+
+// V returns the version major.minor.patch.
+func V(major, minor, patch int) Version {
+	return Version{Major: major, Minor: minor, Patch: patch}
+}
+
+// IsZero reports whether v is the zero Version, i.e. unknown.
+func (v Version) IsZero() bool {
+	return v.Major == 0 && v.Minor == 0 && v.Patch == 0
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Meta != "" {
+		s += "-" + v.Meta
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 as v is older than, the same release as or
+// newer than w. Meta and Commit are ignored.
+func (v Version) Compare(w Version) int {
+	for _, d := range [...]int{v.Major - w.Major, v.Minor - w.Minor, v.Patch - w.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast reports whether v is w or newer.
+func (v Version) AtLeast(w Version) bool {
+	return v.Compare(w) >= 0
+}
+
+// ParseVersion reads the output of `geth version`:
+//
+//	Geth
+//	Version: 1.10.26-stable
+//	Git Commit: e5eb32acee19cc9fca6a03b10283b7484246b15a
+//	...
+func ParseVersion(out string) (Version, error) {
+	var v Version
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Version":
+			var err error
+			if v, err = parseRelease(value, v.Commit); err != nil {
+				return Version{}, err
+			}
+			found = true
+		case "Git Commit":
+			v.Commit = value
+		}
+	}
+	if !found {
+		return Version{}, ErrNoVersion
+	}
+	return v, nil
+}
+
+// parseRelease parses "1.10.26-stable".
+func parseRelease(s, commit string) (Version, error) {
+	release, meta, _ := strings.Cut(s, "-")
+	parts := strings.Split(release, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid geth version %q", s)
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid geth version %q", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Meta: meta, Commit: commit}, nil
+}
@@ -0,0 +1,194 @@
+package gethlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of lines kept by a Buffer created with a
+// non-positive capacity.
+const DefaultCapacity = 5000
+
+// Stream identifies which output of geth a line came from.
+type Stream string
+
+const (
+	Stdout Stream = "stdout"
+	Stderr Stream = "stderr"
+)
+
+// Line is a single line of geth output.
+type Line struct {
+	// Seq increases by one for every line appended to the buffer
+	Seq uint64
+	// Time is when GeNe received the line
+	Time time.Time
+	// Stream the line was written to
+	Stream Stream
+	// Level of the line; lines without one inherit the level of the line before them
+	Level Level
+	// Text is the line without its trailing newline
+	Text string
+	// Event is the parsed log record, nil if the line is not a geth log line
+	Event *Event
+}
+
+// Buffer is a bounded ring buffer of geth output lines. Once full, the
+// oldest lines are dropped.
+type Buffer struct {
+	mu          sync.Mutex
+	lines       []Line
+	start       int
+	count       int
+	seq         uint64
+	lastLevel   Level
+	subscribers map[int]func(Line)
+	nextSub     int
+}
+
+// NewBuffer returns a Buffer holding up to capacity lines.
+func NewBuffer(capacity int) *Buffer {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Buffer{
+		lines:       make([]Line, capacity),
+		subscribers: make(map[int]func(Line)),
+	}
+}
+
+// Append adds a line of output to the buffer and passes it to the subscribers.
+func (b *Buffer) Append(stream Stream, text string) Line {
+	var event *Event
+	level := LevelUnknown
+	if e, err := Parse(text); err == nil {
+		event, level = &e, e.Level
+	}
+
+	b.mu.Lock()
+	if level == LevelUnknown {
+		level = b.lastLevel
+	}
+	b.lastLevel = level
+	b.seq++
+	line := Line{
+		Seq:    b.seq,
+		Time:   time.Now(),
+		Stream: stream,
+		Level:  level,
+		Text:   text,
+		Event:  event,
+	}
+
+	i := (b.start + b.count) % len(b.lines)
+	b.lines[i] = line
+	if b.count < len(b.lines) {
+		b.count++
+	} else {
+		b.start = (b.start + 1) % len(b.lines)
+	}
+
+	subscribers := make([]func(Line), 0, len(b.subscribers))
+	for _, fn := range b.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	b.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(line)
+	}
+	return line
+}
+
+// Lines returns a copy of the buffered lines, oldest first.
+func (b *Buffer) Lines() []Line {
+	return b.Since(0)
+}
+
+// Since returns a copy of the buffered lines with a sequence number greater than seq, oldest first.
+func (b *Buffer) Since(seq uint64) []Line {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var lines []Line
+	for i := 0; i < b.count; i++ {
+		line := b.lines[(b.start+i)%len(b.lines)]
+		if line.Seq > seq {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Seq returns the sequence number of the most recent line.
+func (b *Buffer) Seq() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.seq
+}
+
+// Clear drops every buffered line. Sequence numbers keep increasing.
+func (b *Buffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.start, b.count = 0, 0
+}
+
+// Subscribe registers fn to be called with every appended line and returns
+// a function that removes the subscription. fn is called synchronously
+// from the writer and must not block.
+func (b *Buffer) Subscribe(fn func(Line)) (cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextSub
+	b.nextSub++
+	b.subscribers[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Writer returns an io.Writer that splits its input into lines and appends
+// them to the buffer as coming from stream. Close flushes an unterminated last line.
+func (b *Buffer) Writer(stream Stream) io.WriteCloser {
+	return &lineWriter{buf: b, stream: stream}
+}
+
+type lineWriter struct {
+	buf     *Buffer
+	stream  Stream
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.buf.Append(w.stream, string(bytes.TrimRight(w.partial[:i], "\r")))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.buf.Append(w.stream, string(w.partial))
+		w.partial = nil
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package gethlog collects and interprets the output of a geth process.
+package gethlog
+
+import "strings"
+
+// Level is a geth log level.
+type Level int
+
+const (
+	// LevelUnknown is used for lines that do not carry a level, such as stack traces
+	LevelUnknown Level = iota
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelCrit
+)
+
+// Levels lists the known levels from the most to the least verbose.
+var Levels = []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelCrit}
+
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelCrit:
+		return "CRIT"
+	default:
+		return ""
+	}
+}
+
+// ParseLevel converts a level name as printed by geth, e.g. "INFO", "eror"
+// or "warn", into a Level. Unrecognised names return LevelUnknown.
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(s) {
+	case "TRACE", "TRCE":
+		return LevelTrace
+	case "DEBUG", "DBUG":
+		return LevelDebug
+	case "INFO":
+		return LevelInfo
+	case "WARN":
+		return LevelWarn
+	case "ERROR", "EROR":
+		return LevelError
+	case "CRIT", "FATAL":
+		return LevelCrit
+	default:
+		return LevelUnknown
+	}
+}
@@ -0,0 +1,248 @@
+package gethlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotLogLine is returned when a line is in neither of geth's log formats,
+// e.g. a panic stack trace or the output of a subcommand.
+var ErrNotLogLine = errors.New("not a geth log line")
+
+// Event is a structured geth log record.
+type Event struct {
+	// Level of the record
+	Level Level
+	// Time the record was logged. Terminal format lines carry no year, so the
+	// most recent matching date is assumed.
+	Time time.Time
+	// Message is the log message without its key/value context
+	Message string
+	// Context holds the key/value pairs in the order geth printed them
+	Context []KV
+}
+
+// KV is one key/value pair of an Event's context.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// Get returns the value of the first context pair with the given key.
+func (e Event) Get(key string) (string, bool) {
+	for _, kv := range e.Context {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse turns a line in either geth's terminal format or its --log.json
+// format into an Event.
+func Parse(line string) (Event, error) {
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return ParseJSON(line)
+	}
+	return ParseTerminal(line)
+}
+
+// ParseTerminal parses a line in geth's default terminal format:
+//
+//	INFO [10-18|12:00:00.000] Imported new chain segment               number=1 hash=0x1234..5678
+//
+// The header may also carry a source location, as in [10-18|12:00:00.000|eth/handler.go:123].
+func ParseTerminal(line string) (Event, error) {
+	var e Event
+
+	open := strings.IndexByte(line, '[')
+	closing := strings.IndexByte(line, ']')
+	if open < 0 || closing < open {
+		return e, ErrNotLogLine
+	}
+	e.Level = ParseLevel(strings.TrimSpace(line[:open]))
+	if e.Level == LevelUnknown {
+		return e, ErrNotLogLine
+	}
+
+	header := strings.Split(line[open+1:closing], "|")
+	if len(header) < 2 {
+		return e, ErrNotLogLine
+	}
+	t, err := parseTerminalTime(header[0], header[1], time.Now())
+	if err != nil {
+		return e, err
+	}
+	e.Time = t
+
+	body := strings.TrimSpace(line[closing+1:])
+	toks := tokenize(body)
+
+	// the context is the longest run of key=value tokens at the end of the line
+	ctxStart := len(toks)
+	for ctxStart > 0 && toks[ctxStart-1].key != "" {
+		ctxStart--
+	}
+	if ctxStart == 0 && len(toks) > 0 {
+		// a message is always present, even if it looks like a key=value pair
+		ctxStart = 1
+	}
+	if ctxStart < len(toks) {
+		e.Message = strings.TrimSpace(body[:toks[ctxStart].offset])
+	} else {
+		e.Message = body
+	}
+	for _, tok := range toks[ctxStart:] {
+		e.Context = append(e.Context, KV{Key: tok.key, Value: tok.value})
+	}
+
+	return e, nil
+}
+
+// parseTerminalTime parses the "01-02" date and "15:04:05.000" time of a
+// terminal header, picking the year that puts the record closest before now.
+func parseTerminalTime(date, clock string, now time.Time) (time.Time, error) {
+	t, err := time.ParseInLocation("01-02 15:04:05.000", date+" "+clock, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid log timestamp %q: %w", date+"|"+clock, err)
+	}
+	t = t.AddDate(now.Year()-t.Year(), 0, 0)
+	// a record from late December read in early January
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, nil
+}
+
+// token is a whitespace separated word of a terminal log line. Tokens of
+// the form key=value have key set and value unquoted.
+type token struct {
+	offset int
+	key    string
+	value  string
+}
+
+// tokenize splits s on whitespace, keeping double quoted values together.
+func tokenize(s string) []token {
+	var toks []token
+	i := 0
+	for i < len(s) {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+
+		start := i
+		inQuote := false
+		for i < len(s) && (inQuote || (s[i] != ' ' && s[i] != '\t')) {
+			switch {
+			case s[i] == '\\' && inQuote:
+				i++
+			case s[i] == '"':
+				inQuote = !inQuote
+			}
+			i++
+		}
+		if i > len(s) {
+			i = len(s)
+		}
+
+		tok := token{offset: start}
+		word := s[start:i]
+		if eq := strings.IndexByte(word, '='); eq > 0 && isKey(word[:eq]) {
+			tok.key = word[:eq]
+			tok.value = word[eq+1:]
+			if strings.HasPrefix(tok.value, `"`) {
+				v, err := strconv.Unquote(tok.value)
+				if err != nil {
+					// not a quoted value after all, treat the word as part of the message
+					tok.key, tok.value = "", ""
+				} else {
+					tok.value = v
+				}
+			}
+		}
+		toks = append(toks, tok)
+	}
+	return toks
+}
+
+func isKey(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_' || r == '-' || r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParseJSON parses a line written with --log.json. Both the legacy
+// ("t", "lvl", "msg") and the newer ("time", "level", "msg") key names are understood.
+func ParseJSON(line string) (Event, error) {
+	var e Event
+
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return e, ErrNotLogLine
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return e, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return e, ErrNotLogLine
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return e, err
+		}
+		value := jsonValue(raw)
+
+		switch key {
+		case "t", "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return e, fmt.Errorf("invalid log timestamp %q: %w", value, err)
+			}
+			e.Time = t
+		case "lvl", "level":
+			e.Level = ParseLevel(value)
+		case "msg", "message":
+			e.Message = value
+		default:
+			e.Context = append(e.Context, KV{Key: key, Value: value})
+		}
+	}
+
+	if e.Level == LevelUnknown {
+		return e, ErrNotLogLine
+	}
+	return e, nil
+}
+
+// jsonValue renders a JSON value as the text geth would print in terminal
+// format: strings unquoted, everything else as compact JSON.
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
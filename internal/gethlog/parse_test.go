@@ -0,0 +1,350 @@
+package gethlog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTerminal(t *testing.T) {
+	tests := []struct {
+		line    string
+		level   Level
+		message string
+		context []KV
+	}{
+		{
+			line:    `INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...`,
+			level:   LevelInfo,
+			message: "Starting Geth on Ethereum mainnet...",
+		},
+		{
+			line:    `INFO [10-18|12:00:01.123] Imported new chain segment               blocks=1 txs=154 mgas=14.919 elapsed=97.512ms mgasps=152.997 number=15,789,123 hash=2ce2ad..c3f6e1 dirty=253.43MiB`,
+			level:   LevelInfo,
+			message: "Imported new chain segment",
+			context: []KV{
+				{"blocks", "1"}, {"txs", "154"}, {"mgas", "14.919"}, {"elapsed", "97.512ms"}, {"mgasps", "152.997"},
+				{"number", "15,789,123"}, {"hash", "2ce2ad..c3f6e1"}, {"dirty", "253.43MiB"},
+			},
+		},
+		{
+			line:    `WARN [10-18|12:00:02.000] Snapshot extension registration failed   peer=3b2a7c0d err="peer connected on snap without compatible eth support"`,
+			level:   LevelWarn,
+			message: "Snapshot extension registration failed",
+			context: []KV{{"peer", "3b2a7c0d"}, {"err", "peer connected on snap without compatible eth support"}},
+		},
+		{
+			line:    `ERROR[10-18|12:00:03.000] Failed to journal state snapshot         err="snapshot [0x1234] missing"`,
+			level:   LevelError,
+			message: "Failed to journal state snapshot",
+			context: []KV{{"err", "snapshot [0x1234] missing"}},
+		},
+		{
+			line:    `EROR[10-18|12:00:03.000] Beacon backfilling failed                err="retrieved hash chain is invalid"`,
+			level:   LevelError,
+			message: "Beacon backfilling failed",
+			context: []KV{{"err", "retrieved hash chain is invalid"}},
+		},
+		{
+			line:    `CRIT [10-18|12:00:04.000] Failed to start the node                 err="listen tcp :30303: bind: address already in use"`,
+			level:   LevelCrit,
+			message: "Failed to start the node",
+			context: []KV{{"err", "listen tcp :30303: bind: address already in use"}},
+		},
+		{
+			line:    `DEBUG[10-18|12:00:05.000|p2p/server.go:733] Dialing peer                             id=8f3c21 addr=1.2.3.4:30303`,
+			level:   LevelDebug,
+			message: "Dialing peer",
+			context: []KV{{"id", "8f3c21"}, {"addr", "1.2.3.4:30303"}},
+		},
+		{
+			// the message is kept even when it looks like a key/value pair
+			line:    `INFO [10-18|12:00:06.000] status=ok`,
+			level:   LevelInfo,
+			message: "status=ok",
+		},
+	}
+	for _, tt := range tests {
+		e, err := ParseTerminal(tt.line)
+		if err != nil {
+			t.Errorf("ParseTerminal(%q): %v", tt.line, err)
+			continue
+		}
+		if e.Level != tt.level || e.Message != tt.message || !reflect.DeepEqual(e.Context, tt.context) {
+			t.Errorf("ParseTerminal(%q) = %v %q %v, want %v %q %v",
+				tt.line, e.Level, e.Message, e.Context, tt.level, tt.message, tt.context)
+		}
+		if e.Time.Month() != time.October || e.Time.Day() != 18 || e.Time.Hour() != 12 {
+			t.Errorf("ParseTerminal(%q) time = %s", tt.line, e.Time)
+		}
+	}
+}
+
+func TestParseNotLogLine(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"Fatal: Failed to register the Ethereum service: database contains incompatible genesis",
+		"panic: runtime error: invalid memory address or nil pointer dereference",
+		"goroutine 1 [running]:",
+		"\t/go/src/runtime/panic.go:838 +0x207",
+		"Geth",
+		"Version: 1.10.25-stable",
+		`{"jsonrpc":"2.0","id":1}`,
+	} {
+		if _, err := Parse(line); err != ErrNotLogLine {
+			t.Errorf("Parse(%q) error = %v, want ErrNotLogLine", line, err)
+		}
+	}
+}
+
+func TestParseTerminalTime(t *testing.T) {
+	now := time.Date(2023, time.January, 2, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		date, clock string
+		want        time.Time
+	}{
+		{"01-02", "09:59:59.500", time.Date(2023, time.January, 2, 9, 59, 59, 500e6, time.Local)},
+		// a record from late December read in early January
+		{"12-31", "23:59:59.000", time.Date(2022, time.December, 31, 23, 59, 59, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseTerminalTime(tt.date, tt.clock, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseTerminalTime(%q, %q) = %s, %v, want %s", tt.date, tt.clock, got, err, tt.want)
+		}
+	}
+	if _, err := parseTerminalTime("13-40", "12:00:00.000", now); err == nil {
+		t.Error("parseTerminalTime accepted an invalid date")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		line    string
+		level   Level
+		time    time.Time
+		message string
+		context []KV
+	}{
+		{
+			// legacy key names
+			line:    `{"blocks":1,"elapsed":"97.512ms","hash":"0x2ce2ad7f35b1d0c33c4b9d0c4d7b6ff7a3b3b0c8e7a2e08f4c0bfb3e96c3f6e1","lvl":"info","msg":"Imported new chain segment","number":15789123,"t":"2022-10-18T12:00:01.123456789+02:00"}`,
+			level:   LevelInfo,
+			time:    time.Date(2022, time.October, 18, 10, 0, 1, 123456789, time.UTC),
+			message: "Imported new chain segment",
+			context: []KV{
+				{"blocks", "1"}, {"elapsed", "97.512ms"},
+				{"hash", "0x2ce2ad7f35b1d0c33c4b9d0c4d7b6ff7a3b3b0c8e7a2e08f4c0bfb3e96c3f6e1"}, {"number", "15789123"},
+			},
+		},
+		{
+			line:    `{"err":"listen tcp :30303: bind: address already in use","lvl":"crit","msg":"Failed to start the node","t":"2022-10-18T12:00:04Z"}`,
+			level:   LevelCrit,
+			time:    time.Date(2022, time.October, 18, 12, 0, 4, 0, time.UTC),
+			message: "Failed to start the node",
+			context: []KV{{"err", "listen tcp :30303: bind: address already in use"}},
+		},
+		{
+			// key names since geth 1.13
+			line:    `{"time":"2023-10-18T12:00:02.000000000Z","level":"warn","msg":"Post-merge network, but no beacon client seen","caps":["eth/68","snap/1"]}`,
+			level:   LevelWarn,
+			time:    time.Date(2023, time.October, 18, 12, 0, 2, 0, time.UTC),
+			message: "Post-merge network, but no beacon client seen",
+			context: []KV{{"caps", `["eth/68","snap/1"]`}},
+		},
+		{
+			line:    `{"time":"2023-10-18T12:00:03Z","level":"error","msg":"Beacon backfilling failed","err":"retrieved hash chain is invalid"}`,
+			level:   LevelError,
+			time:    time.Date(2023, time.October, 18, 12, 0, 3, 0, time.UTC),
+			message: "Beacon backfilling failed",
+			context: []KV{{"err", "retrieved hash chain is invalid"}},
+		},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.line)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.line, err)
+			continue
+		}
+		if e.Level != tt.level || !e.Time.Equal(tt.time) || e.Message != tt.message || !reflect.DeepEqual(e.Context, tt.context) {
+			t.Errorf("Parse(%q) = %v %s %q %v, want %v %s %q %v",
+				tt.line, e.Level, e.Time, e.Message, e.Context, tt.level, tt.time, tt.message, tt.context)
+		}
+	}
+
+	if _, err := ParseJSON(`{"lvl":"info","msg":"x","t":"yesterday"}`); err == nil || err == ErrNotLogLine {
+		t.Errorf("ParseJSON with an invalid timestamp: error = %v", err)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"TRACE": LevelTrace, "trce": LevelTrace,
+		"DEBUG": LevelDebug, "dbug": LevelDebug,
+		"INFO": LevelInfo, "info": LevelInfo,
+		"WARN": LevelWarn, "warn": LevelWarn,
+		"ERROR": LevelError, "eror": LevelError,
+		"CRIT": LevelCrit, "crit": LevelCrit, "FATAL": LevelCrit,
+		"": LevelUnknown, "NOTICE": LevelUnknown,
+	}
+	for s, want := range tests {
+		if got := ParseLevel(s); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestBufferWraparound(t *testing.T) {
+	b := NewBuffer(3)
+	for i := 1; i <= 5; i++ {
+		b.Append(Stdout, fmt.Sprintf("INFO [10-18|12:00:0%d.000] line %d", i, i))
+	}
+	lines := b.Lines()
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	for i, l := range lines {
+		if want := uint64(i + 3); l.Seq != want {
+			t.Errorf("line %d has Seq %d, want %d", i, l.Seq, want)
+		}
+		if want := fmt.Sprintf("line %d", i+3); l.Event == nil || l.Event.Message != want {
+			t.Errorf("line %d is %q, want %q", i, l.Text, want)
+		}
+	}
+	if got := b.Since(4); len(got) != 1 || got[0].Seq != 5 {
+		t.Errorf("Since(4) = %v, want line 5", got)
+	}
+	if got := b.Since(0); len(got) != 3 {
+		t.Errorf("Since(0) returned %d lines, want the 3 kept", len(got))
+	}
+
+	b.Clear()
+	if got := b.Lines(); len(got) != 0 {
+		t.Errorf("Lines after Clear = %v", got)
+	}
+	if l := b.Append(Stdout, "Geth"); l.Seq != 6 {
+		t.Errorf("Seq after Clear = %d, want 6", l.Seq)
+	}
+}
+
+func TestWriterSplitsLines(t *testing.T) {
+	b := NewBuffer(0)
+	w := b.Writer(Stderr)
+	for _, chunk := range []string{"ERROR[10-18|12:00:00.000] Database ", "failure\r\npanic: boom\n", "goroutine 1 [running]:\n\tmain.go:1", ""} {
+		fmt.Fprint(w, chunk)
+	}
+	if got := len(b.Lines()); got != 3 {
+		t.Fatalf("got %d lines before Close, want 3", got)
+	}
+	w.Close()
+
+	lines := b.Lines()
+	want := []string{"ERROR[10-18|12:00:00.000] Database failure", "panic: boom", "goroutine 1 [running]:", "\tmain.go:1"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, l := range lines {
+		if l.Text != want[i] || l.Stream != Stderr {
+			t.Errorf("line %d = %s %q, want stderr %q", i, l.Stream, l.Text, want[i])
+		}
+		// lines without a header inherit the level of the line before them
+		if l.Level != LevelError {
+			t.Errorf("line %d level = %v, want ERROR", i, l.Level)
+		}
+	}
+}
+
+func TestFatalReason(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{
+			name: "nothing",
+			log: `INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...
+INFO [10-18|12:00:00.100] Maximum peer count                       ETH=50 LES=0 total=50`,
+			want: "",
+		},
+		{
+			name: "fatal",
+			log: `INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...
+ERROR[10-18|12:00:00.200] Failed to open database                  err="resource temporarily unavailable"
+Fatal: Failed to create the protocol stack: datadir already used by another process`,
+			want: "Failed to create the protocol stack: datadir already used by another process",
+		},
+		{
+			name: "multi-line fatal",
+			log: `INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...
+Fatal: Failed to register the Ethereum service: database contains incompatible genesis (have d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3, new a3c565fc15c7478862d50ccd6561e3c06b24cc509bf388941c25ea985ce32cb9)
+	use a new datadir or remove the old chain with geth removedb`,
+			want: "Failed to register the Ethereum service: database contains incompatible genesis (have d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3, new a3c565fc15c7478862d50ccd6561e3c06b24cc509bf388941c25ea985ce32cb9)",
+		},
+		{
+			// geth writes Fatal to stdout as well when stderr is redirected
+			name: "fatal on both streams",
+			log: `Fatal: Error starting protocol stack: listen tcp :30303: bind: address already in use
+Fatal: Error starting protocol stack: listen tcp :30303: bind: address already in use`,
+			want: "Error starting protocol stack: listen tcp :30303: bind: address already in use",
+		},
+		{
+			name: "crit",
+			log: `INFO [10-18|12:00:00.000] Starting peer-to-peer node               instance=Geth/v1.10.25-stable/linux-amd64/go1.18.5
+ERROR[10-18|12:00:00.100] Unavailable modules in HTTP API list     unavailable=[foo] available="[admin debug web3 eth]"
+CRIT [10-18|12:00:00.200] Failed to start the node                 err="listen tcp :30303: bind: address already in use"`,
+			want: "Failed to start the node: listen tcp :30303: bind: address already in use",
+		},
+		{
+			name: "json crit",
+			log:  `{"err":"missing trie node","lvl":"crit","msg":"Failed to recover state","t":"2022-10-18T12:00:04Z"}`,
+			want: "Failed to recover state: missing trie node",
+		},
+		{
+			name: "panic",
+			log: `INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...
+ERROR[10-18|12:00:00.100] Snapshot extension registration failed   err="peer connected on snap without compatible eth support"
+panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x8d3f5a]
+
+goroutine 1 [running]:`,
+			want: "panic: runtime error: invalid memory address or nil pointer dereference",
+		},
+		{
+			name: "last error",
+			log: `ERROR[10-18|12:00:00.100] Beacon backfilling failed                err="retrieved hash chain is invalid"
+ERROR[10-18|12:00:00.200] Failed to journal state snapshot
+INFO [10-18|12:00:00.300] Looking for peers                        peercount=0 tried=10 static=0`,
+			want: "Failed to journal state snapshot",
+		},
+	}
+	for _, tt := range tests {
+		b := NewBuffer(0)
+		w := b.Writer(Stderr)
+		fmt.Fprintln(w, tt.log)
+		w.Close()
+		if got := FatalReason(b.Lines()); got != tt.want {
+			t.Errorf("%s: FatalReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFatalReasonAfterWraparound(t *testing.T) {
+	b := NewBuffer(4)
+	fmt.Fprintln(b.Writer(Stdout), "Fatal: Failed to create the protocol stack: datadir already used by another process")
+	start := b.Seq()
+	lines := []string{
+		`INFO [10-18|12:00:00.000] Starting Geth on Ethereum mainnet...`,
+		`CRIT [10-18|12:00:00.100] Failed to start the node                 err="listen tcp :8545: bind: address already in use"`,
+	}
+	for i := 0; i < 3; i++ {
+		lines = append(lines, fmt.Sprintf(`INFO [10-18|12:00:01.%03d] Looking for peers                        peercount=0 tried=%d static=0`, i, i))
+	}
+	fmt.Fprintln(b.Writer(Stdout), strings.Join(lines, "\n"))
+
+	// the Fatal line of the previous run fell out of the buffer, the CRIT record is kept
+	if got, want := FatalReason(b.Since(start)), "Failed to start the node: listen tcp :8545: bind: address already in use"; got != want {
+		t.Errorf("FatalReason = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package gethlog
+
+import "strings"
+
+// FatalReason picks the line that most likely explains why geth failed to
+// start or exited from lines, oldest first, and returns it without its log
+// header. It prefers the "Fatal: " message geth prints before exiting, then
+// CRIT records, a Go panic, and finally the last ERROR record. It returns an
+// empty string when none of those is present.
+func FatalReason(lines []Line) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if text := strings.TrimSpace(lines[i].Text); strings.HasPrefix(text, "Fatal: ") {
+			return strings.TrimPrefix(text, "Fatal: ")
+		}
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if e := lines[i].Event; e != nil && e.Level == LevelCrit {
+			return describe(*e)
+		}
+	}
+	for _, l := range lines {
+		if strings.HasPrefix(l.Text, "panic: ") {
+			return l.Text
+		}
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if e := lines[i].Event; e != nil && e.Level == LevelError {
+			return describe(*e)
+		}
+	}
+	return ""
+}
+
+// describe returns the message of e followed by its err value, if any.
+func describe(e Event) string {
+	if err, ok := e.Get("err"); ok {
+		return e.Message + ": " + err
+	}
+	return e.Message
+}
@@ -0,0 +1,166 @@
+// Package mining changes the miner settings of a running geth through the
+// miner_ API, so they apply without restarting it.
+package mining
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gene/internal/config"
+	"gene/internal/rpc"
+)
+
+// Fields lists the config.UserInputForNodeConfig fields Apply can change at runtime.
+var Fields = []string{
+	"UserAddress",
+	"MinerThreads",
+	"MinerMinimumGasPrice",
+	"MinerExtraData",
+	"MinerGasLimit",
+	"MinerRecommit",
+}
+
+// errInvalidParams is the JSON-RPC code for a call with the wrong parameters.
+const errInvalidParams = -32602
+
+// Start starts mining with the MinerThreads of cfg, all cores when it is
+// empty or 0. Releases whose miner_start takes no thread count are started
+// without one.
+func Start(ctx context.Context, client *rpc.Client, cfg config.UserInputForNodeConfig) error {
+	threads, err := threads(cfg)
+	if err != nil {
+		return err
+	}
+	err = client.MinerStart(ctx, threads)
+	var rpcErr *rpc.Error
+	if threads > 0 && errors.As(err, &rpcErr) && rpcErr.Code == errInvalidParams {
+		return client.MinerStart(ctx, 0)
+	}
+	return err
+}
+
+// Apply sends the miner settings of cfg that differ from old to geth and
+// returns the fields it changed. Fields left empty keep the value geth
+// runs with, since there is no default to reset them to. MinerThreads only
+// applies while geth is mining, the next Start uses it otherwise.
+func Apply(ctx context.Context, client *rpc.Client, old, cfg config.UserInputForNodeConfig) ([]string, error) {
+	var applied []string
+	apply := func(field string, call func() (bool, error)) error {
+		ok, err := call()
+		if err == nil && !ok {
+			err = errors.New("geth refused the change")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		applied = append(applied, field)
+		return nil
+	}
+
+	if cfg.UserAddress != "" && cfg.UserAddress != old.UserAddress {
+		if err := apply("UserAddress", func() (bool, error) {
+			return client.MinerSetEtherbase(ctx, cfg.UserAddress)
+		}); err != nil {
+			return applied, err
+		}
+	}
+
+	if cfg.MinerMinimumGasPrice != "" && cfg.MinerMinimumGasPrice != old.MinerMinimumGasPrice {
+		price, ok := new(big.Int).SetString(cfg.MinerMinimumGasPrice, 10)
+		if !ok {
+			return applied, fmt.Errorf("MinerMinimumGasPrice: %q is not a number", cfg.MinerMinimumGasPrice)
+		}
+		if err := apply("MinerMinimumGasPrice", func() (bool, error) {
+			return client.MinerSetGasPrice(ctx, price)
+		}); err != nil {
+			return applied, err
+		}
+	}
+
+	if cfg.MinerExtraData != old.MinerExtraData {
+		if err := apply("MinerExtraData", func() (bool, error) {
+			return client.MinerSetExtra(ctx, cfg.MinerExtraData)
+		}); err != nil {
+			return applied, err
+		}
+	}
+
+	// the gas target has no miner_ call, and geth ignores it since London
+	if cfg.MinerGasLimit != "" && cfg.MinerGasLimit != old.MinerGasLimit {
+		limit, err := strconv.ParseUint(cfg.MinerGasLimit, 10, 64)
+		if err != nil {
+			return applied, fmt.Errorf("MinerGasLimit: %w", err)
+		}
+		if err := apply("MinerGasLimit", func() (bool, error) {
+			return client.MinerSetGasLimit(ctx, limit)
+		}); err != nil {
+			return applied, err
+		}
+	}
+
+	if cfg.MinerRecommit != "" && cfg.MinerRecommit != old.MinerRecommit {
+		interval, err := time.ParseDuration(cfg.MinerRecommit)
+		if err != nil {
+			return applied, fmt.Errorf("MinerRecommit: %w", err)
+		}
+		if err := apply("MinerRecommit", func() (bool, error) {
+			return true, client.MinerSetRecommitInterval(ctx, interval)
+		}); err != nil {
+			return applied, err
+		}
+	}
+
+	if cfg.MinerThreads != old.MinerThreads {
+		mining, err := client.Mining(ctx)
+		if err != nil {
+			return applied, fmt.Errorf("MinerThreads: %w", err)
+		}
+		if mining {
+			// miner_start changes the thread count of a running miner
+			if err := apply("MinerThreads", func() (bool, error) {
+				return true, Start(ctx, client, cfg)
+			}); err != nil {
+				return applied, err
+			}
+		}
+	}
+
+	return applied, nil
+}
+
+// Merge returns running with the runtime miner settings of cfg applied, the
+// configuration geth effectively runs with after Apply.
+func Merge(running, cfg config.UserInputForNodeConfig, applied []string) config.UserInputForNodeConfig {
+	for _, field := range applied {
+		switch field {
+		case "UserAddress":
+			running.UserAddress = cfg.UserAddress
+		case "MinerThreads":
+			running.MinerThreads = cfg.MinerThreads
+		case "MinerMinimumGasPrice":
+			running.MinerMinimumGasPrice = cfg.MinerMinimumGasPrice
+		case "MinerExtraData":
+			running.MinerExtraData = cfg.MinerExtraData
+		case "MinerGasLimit":
+			running.MinerGasLimit = cfg.MinerGasLimit
+		case "MinerRecommit":
+			running.MinerRecommit = cfg.MinerRecommit
+		}
+	}
+	return running
+}
+
+func threads(cfg config.UserInputForNodeConfig) (int, error) {
+	if cfg.MinerThreads == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(cfg.MinerThreads)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("MinerThreads: %q is not a thread count", cfg.MinerThreads)
+	}
+	return n, nil
+}
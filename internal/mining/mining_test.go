@@ -0,0 +1,302 @@
+package mining
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gene/internal/config"
+	"gene/internal/rpc"
+)
+
+// minerStub is a geth HTTP-RPC server with the miner_ API. It records
+// every call as the method followed by its JSON encoded parameters.
+type minerStub struct {
+	// mining is the answer to eth_mining
+	mining bool
+	// fail returns the error to answer method with, nil to succeed
+	fail func(method string, params []json.RawMessage) *rpc.Error
+	// refused are the miner_set calls answered with false
+	refused map[string]bool
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *minerStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, _ := json.Marshal(req.Params)
+	if req.Params == nil {
+		params = []byte("[]")
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, req.Method+string(params))
+	s.mu.Unlock()
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	var rpcErr *rpc.Error
+	if s.fail != nil {
+		rpcErr = s.fail(req.Method, req.Params)
+	}
+	switch {
+	case rpcErr != nil:
+		resp["error"] = rpcErr
+	case req.Method == "eth_mining":
+		resp["result"] = s.mining
+	case strings.HasPrefix(req.Method, "miner_set") && req.Method != "miner_setRecommitInterval":
+		resp["result"] = !s.refused[req.Method]
+	default:
+		resp["result"] = nil
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// dial serves s and returns a client connected to it.
+func (s *minerStub) dial(t *testing.T) *rpc.Client {
+	t.Helper()
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	client, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (s *minerStub) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// running is the miner configuration geth runs with in the tests.
+var running = config.UserInputForNodeConfig{
+	DataDir:              "/data/geth",
+	UserAddress:          "0x1111111111111111111111111111111111111111",
+	MinerThreads:         "1",
+	MinerMinimumGasPrice: "1",
+	MinerExtraData:       "old",
+	MinerGasLimit:        "30000000",
+	MinerRecommit:        "2s",
+}
+
+func TestApply(t *testing.T) {
+	changed := running
+	changed.UserAddress = "0x2222222222222222222222222222222222222222"
+	changed.MinerThreads = "4"
+	changed.MinerMinimumGasPrice = "1000000000"
+	changed.MinerExtraData = "gene"
+	changed.MinerGasLimit = "36000000"
+	changed.MinerRecommit = "3s"
+
+	emptied := running
+	emptied.UserAddress = ""
+	emptied.MinerMinimumGasPrice = ""
+	emptied.MinerExtraData = ""
+	emptied.MinerGasLimit = ""
+	emptied.MinerRecommit = ""
+
+	threads := running
+	threads.MinerThreads = "4"
+	allCores := running
+	allCores.MinerThreads = ""
+	badPrice := running
+	badPrice.MinerMinimumGasPrice = "1 gwei"
+
+	tests := []struct {
+		name    string
+		cfg     config.UserInputForNodeConfig
+		mining  bool
+		refused map[string]bool
+		fail    string
+		calls   []string
+		applied []string
+		err     string
+	}{
+		{
+			name:   "all changed while mining",
+			cfg:    changed,
+			mining: true,
+			calls: []string{
+				`miner_setEtherbase["0x2222222222222222222222222222222222222222"]`,
+				`miner_setGasPrice["0x3b9aca00"]`,
+				`miner_setExtra["gene"]`,
+				`miner_setGasLimit["0x2255100"]`,
+				`miner_setRecommitInterval[3000]`,
+				`eth_mining[]`,
+				`miner_start[4]`,
+			},
+			applied: []string{"UserAddress", "MinerMinimumGasPrice", "MinerExtraData", "MinerGasLimit", "MinerRecommit", "MinerThreads"},
+		},
+		{
+			name: "nothing changed",
+			cfg:  running,
+		},
+		{
+			// geth has no default to reset them to, only the extra data can be cleared
+			name:    "emptied fields are skipped",
+			cfg:     emptied,
+			calls:   []string{`miner_setExtra[""]`},
+			applied: []string{"MinerExtraData"},
+		},
+		{
+			name:  "threads while not mining",
+			cfg:   threads,
+			calls: []string{`eth_mining[]`},
+		},
+		{
+			name:    "all cores while mining",
+			cfg:     allCores,
+			mining:  true,
+			calls:   []string{`eth_mining[]`, `miner_start[]`},
+			applied: []string{"MinerThreads"},
+		},
+		{
+			name:    "refused",
+			cfg:     changed,
+			refused: map[string]bool{"miner_setExtra": true},
+			calls: []string{
+				`miner_setEtherbase["0x2222222222222222222222222222222222222222"]`,
+				`miner_setGasPrice["0x3b9aca00"]`,
+				`miner_setExtra["gene"]`,
+			},
+			applied: []string{"UserAddress", "MinerMinimumGasPrice"},
+			err:     "MinerExtraData: geth refused the change",
+		},
+		{
+			name: "call failed",
+			cfg:  changed,
+			fail: "miner_setGasLimit",
+			calls: []string{
+				`miner_setEtherbase["0x2222222222222222222222222222222222222222"]`,
+				`miner_setGasPrice["0x3b9aca00"]`,
+				`miner_setExtra["gene"]`,
+				`miner_setGasLimit["0x2255100"]`,
+			},
+			applied: []string{"UserAddress", "MinerMinimumGasPrice", "MinerExtraData"},
+			err:     "MinerGasLimit: the method miner_setGasLimit does not exist/is not available (code -32601)",
+		},
+		{
+			name: "invalid gas price",
+			cfg:  badPrice,
+			err:  `MinerMinimumGasPrice: "1 gwei" is not a number`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &minerStub{mining: tt.mining, refused: tt.refused}
+			if tt.fail != "" {
+				stub.fail = func(method string, _ []json.RawMessage) *rpc.Error {
+					if method == tt.fail {
+						return &rpc.Error{Code: -32601, Message: "the method " + method + " does not exist/is not available"}
+					}
+					return nil
+				}
+			}
+			applied, err := Apply(context.Background(), stub.dial(t), running, tt.cfg)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+			if !reflect.DeepEqual(applied, tt.applied) {
+				t.Errorf("applied = %q, want %q", applied, tt.applied)
+			}
+			if calls := stub.recorded(); !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("calls = %q, want %q", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	invalidParams := func(method string, params []json.RawMessage) *rpc.Error {
+		if method == "miner_start" && len(params) > 0 {
+			return &rpc.Error{Code: -32602, Message: "too many arguments, want at most 0"}
+		}
+		return nil
+	}
+	failed := func(string, []json.RawMessage) *rpc.Error {
+		return &rpc.Error{Code: -32000, Message: "etherbase missing"}
+	}
+
+	tests := []struct {
+		name    string
+		threads string
+		fail    func(string, []json.RawMessage) *rpc.Error
+		calls   []string
+		err     string
+	}{
+		{"threads", "2", nil, []string{`miner_start[2]`}, ""},
+		{"all cores", "", nil, []string{`miner_start[]`}, ""},
+		{"zero is all cores", "0", nil, []string{`miner_start[]`}, ""},
+		{"release without a thread count", "2", invalidParams, []string{`miner_start[2]`, `miner_start[]`}, ""},
+		{"other errors are returned", "2", failed, []string{`miner_start[2]`}, "etherbase missing (code -32000)"},
+		{"invalid threads", "two", nil, nil, `MinerThreads: "two" is not a thread count`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &minerStub{fail: tt.fail}
+			err := Start(context.Background(), stub.dial(t), config.UserInputForNodeConfig{MinerThreads: tt.threads})
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+			if calls := stub.recorded(); !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("calls = %q, want %q", calls, tt.calls)
+			}
+		})
+	}
+
+	// the fallback is only for the thread count
+	stub := &minerStub{fail: func(string, []json.RawMessage) *rpc.Error {
+		return &rpc.Error{Code: -32602, Message: "invalid argument"}
+	}}
+	var rpcErr *rpc.Error
+	if err := Start(context.Background(), stub.dial(t), config.UserInputForNodeConfig{}); !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+		t.Errorf("Start without threads = %v, want the -32602 error", err)
+	}
+	if calls := stub.recorded(); len(calls) != 1 {
+		t.Errorf("calls = %q, want no retry", calls)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cfg := running
+	cfg.DataDir = "/data/other"
+	cfg.UserAddress = "0x2222222222222222222222222222222222222222"
+	cfg.MinerThreads = "4"
+	cfg.MinerExtraData = "gene"
+	cfg.MinerRecommit = "3s"
+
+	got := Merge(running, cfg, []string{"UserAddress", "MinerThreads"})
+	want := running
+	want.UserAddress = cfg.UserAddress
+	want.MinerThreads = cfg.MinerThreads
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+
+	if got := Merge(running, cfg, nil); !reflect.DeepEqual(got, running) {
+		t.Errorf("Merge without applied fields = %+v, want the running config", got)
+	}
+
+	// every field Apply changes can be merged
+	all := Merge(running, cfg, Fields)
+	cfg.DataDir = running.DataDir
+	if !reflect.DeepEqual(all, cfg) {
+		t.Errorf("Merge of every field = %+v, want %+v", all, cfg)
+	}
+}
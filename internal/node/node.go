@@ -0,0 +1,347 @@
+// Package node supervises the geth process launched by GeNe.
+package node
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"gene/internal/gethlog"
+)
+
+// DefaultStopTimeout is how long Stop waits for geth to exit after SIGINT
+// before falling back to SIGKILL.
+const DefaultStopTimeout = 30 * time.Second
+
+// DefaultTailLines is the number of output lines kept for crash reports.
+const DefaultTailLines = 20
+
+var (
+	// ErrAlreadyRunning is returned by Start when the node already owns a geth process.
+	ErrAlreadyRunning = errors.New("geth is already running")
+	// ErrNotRunning is returned by Stop when the node has no geth process to stop.
+	ErrNotRunning = errors.New("geth is not running")
+	// ErrNodeRemoved is returned by Start once the node was removed from its Registry.
+	ErrNodeRemoved = errors.New("node was removed")
+)
+
+// Spec describes how to launch geth.
+type Spec struct {
+	// Binary is the geth executable to run, GethFileLocation when empty
+	Binary string
+	// Args are the command line arguments passed to geth
+	Args []string
+	// Env holds NAME=value pairs added to GeNe's own environment for geth
+	Env []string
+	// Restart decides what happens when geth exits without being asked to
+	Restart RestartConfig
+	// Ready decides when the started geth counts as Ready
+	Ready ReadyConfig
+}
+
+// Node owns a single geth child process. Only the process started by Start
+// is ever signalled, so other geth instances on the machine are left alone.
+type Node struct {
+	// GethFileLocation is the location of the geth binary
+	GethFileLocation string
+	// StopTimeout is how long to wait for a clean shutdown after SIGINT (default: 30s)
+	StopTimeout time.Duration
+	// OnExit is called, from its own goroutine, every time the geth process exits
+	OnExit func(ExitStatus)
+	// OnCrash is called, from its own goroutine, when geth exits without being asked to
+	OnCrash func(CrashReport)
+	// OnNotReady is called, from its own goroutine, when geth runs but does not become Ready in time
+	OnNotReady func(*ReadyError)
+
+	mu           sync.Mutex
+	logs         *gethlog.Buffer
+	state        State
+	listeners    []func(State)
+	spec         Spec
+	cmd          *exec.Cmd
+	done         chan struct{}
+	stopping     time.Time
+	killed       bool
+	last         ExitStatus
+	restarts     []time.Time
+	restartTimer *time.Timer
+	removed      bool
+}
+
+// ExitStatus describes how a geth process ended.
+type ExitStatus struct {
+	// PID of the process that exited
+	PID int
+	// ExitCode is the process exit code, or -1 if it was terminated by a signal
+	ExitCode int
+	// State is the human readable process state, e.g. "exit status 1" or "signal: killed"
+	State string
+	// Killed is true when geth ignored SIGINT and had to be sent SIGKILL
+	Killed bool
+	// ShutdownTime is the time between SIGINT and exit, zero if geth exited on its own
+	ShutdownTime time.Duration
+	// LastLines are the last lines geth wrote to stdout and stderr
+	LastLines []string
+	// Reason is the log line that most likely explains an unexpected exit, if any
+	Reason string
+}
+
+func (s ExitStatus) String() string {
+	msg := fmt.Sprintf("geth (pid %d) exited: %s", s.PID, s.State)
+	if s.ShutdownTime > 0 {
+		msg += fmt.Sprintf(" after %s", s.ShutdownTime.Round(time.Millisecond))
+	}
+	if s.Killed {
+		msg += " (killed after stop timeout)"
+	}
+	if s.Reason != "" {
+		msg += "; likely cause: " + s.Reason
+	}
+	return msg
+}
+
+// Logs returns the buffer that collects the output of every geth process
+// started by the node.
+func (n *Node) Logs() *gethlog.Buffer {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.logs == nil {
+		n.logs = gethlog.NewBuffer(gethlog.DefaultCapacity)
+	}
+	return n.logs
+}
+
+// Running reports whether the node currently owns a geth process.
+func (n *Node) Running() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.cmd != nil
+}
+
+// Start launches geth as described by spec. The node stays Starting until
+// geth is ready, see ReadyConfig. It fails with ErrAlreadyRunning
+// unless the node is Stopped or Crashed, so a second click can never launch
+// another geth on the same datadir. Starting by hand cancels any pending
+// automatic restart and resets the restart count.
+func (n *Node) Start(spec Spec) error {
+	n.mu.Lock()
+	n.cancelRestart()
+	n.restarts = nil
+	n.mu.Unlock()
+
+	return n.start(spec)
+}
+
+func (n *Node) start(spec Spec) error {
+	logs := n.Logs()
+	out := output{
+		logs:     logs,
+		startSeq: logs.Seq(),
+		stdout:   logs.Writer(gethlog.Stdout),
+		stderr:   logs.Writer(gethlog.Stderr),
+	}
+
+	// the process is launched under n.mu, so Stop never sees a Starting
+	// node without the process it has to signal
+	n.mu.Lock()
+	if n.removed {
+		n.mu.Unlock()
+		out.stdout.Close()
+		out.stderr.Close()
+		return ErrNodeRemoved
+	}
+	if n.state.Active() {
+		n.mu.Unlock()
+		out.stdout.Close()
+		out.stderr.Close()
+		return ErrAlreadyRunning
+	}
+	binary := spec.Binary
+	if binary == "" {
+		binary = n.GethFileLocation
+	}
+	cmd := exec.Command(binary, spec.Args...)
+	cmd.Stdout = out.stdout
+	cmd.Stderr = out.stderr
+	if len(spec.Env) > 0 {
+		cmd.Env = append(os.Environ(), spec.Env...)
+	}
+	if err := cmd.Start(); err != nil {
+		n.state = Stopped
+		n.mu.Unlock()
+		n.notify()
+		out.stdout.Close()
+		out.stderr.Close()
+		return err
+	}
+	n.state = Starting
+	n.spec = spec
+	n.cmd = cmd
+	n.done = make(chan struct{})
+	n.stopping = time.Time{}
+	n.killed = false
+	go n.wait(cmd, out, n.done)
+	go n.awaitReady(cmd, out, n.done, spec.Ready)
+	n.mu.Unlock()
+	n.notify()
+
+	return nil
+}
+
+// output routes the stdout and stderr of one geth process into the node logs.
+type output struct {
+	logs     *gethlog.Buffer
+	startSeq uint64
+	stdout   io.WriteCloser
+	stderr   io.WriteCloser
+}
+
+// tail returns up to max of the last lines written by the process.
+func (o output) tail(max int) []string {
+	lines := o.logs.Since(o.startSeq)
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	tail := make([]string, len(lines))
+	for i, l := range lines {
+		tail[i] = l.Text
+	}
+	return tail
+}
+
+// Restart stops the running geth process, if any, and starts it again
+// as described by spec.
+func (n *Node) Restart(spec Spec) error {
+	if _, err := n.Stop(); err != nil && err != ErrNotRunning {
+		return err
+	}
+	return n.Start(spec)
+}
+
+// wait reaps the geth process, records how it exited and applies the
+// restart policy when the exit was not requested.
+func (n *Node) wait(cmd *exec.Cmd, out output, done chan struct{}) {
+	// the exit error carries nothing that ProcessState does not
+	_ = cmd.Wait()
+	out.stdout.Close()
+	out.stderr.Close()
+
+	n.mu.Lock()
+	status := ExitStatus{
+		PID:       cmd.Process.Pid,
+		ExitCode:  cmd.ProcessState.ExitCode(),
+		State:     cmd.ProcessState.String(),
+		Killed:    n.killed,
+		LastLines: out.tail(DefaultTailLines),
+	}
+	var crash *CrashReport
+	if n.stopping.IsZero() {
+		n.state = Crashed
+		status.Reason = gethlog.FatalReason(out.logs.Since(out.startSeq))
+		r := n.planRestart(status)
+		crash = &r
+	} else {
+		status.ShutdownTime = time.Since(n.stopping)
+		n.state = Stopped
+	}
+	n.last = status
+	n.cmd = nil
+	close(done)
+	onExit := n.OnExit
+	n.mu.Unlock()
+	n.notify()
+
+	if onExit != nil {
+		onExit(status)
+	}
+	if crash != nil {
+		n.reportCrash(*crash)
+	}
+}
+
+// Stop sends SIGINT to the geth process and waits up to StopTimeout for it
+// to exit before sending SIGKILL. A pending automatic restart is cancelled.
+func (n *Node) Stop() (ExitStatus, error) {
+	n.mu.Lock()
+	cmd, done := n.cmd, n.done
+	if cmd == nil {
+		cancelled := n.cancelRestart()
+		if cancelled {
+			n.state = Stopped
+		}
+		last := n.last
+		n.mu.Unlock()
+		if cancelled {
+			n.notify()
+			return last, nil
+		}
+		return ExitStatus{}, ErrNotRunning
+	}
+	prev, requested := n.state, n.stopping.IsZero()
+	if requested {
+		n.stopping = time.Now()
+		n.state = Stopping
+	}
+	n.mu.Unlock()
+	n.notify()
+
+	timeout := n.StopTimeout
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+
+	if err := cmd.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		// geth did not get the request, so it keeps running as before
+		n.mu.Lock()
+		restored := requested && n.cmd == cmd && n.state == Stopping
+		if restored {
+			n.stopping = time.Time{}
+			n.state = prev
+		}
+		n.mu.Unlock()
+		if restored {
+			n.notify()
+		}
+		return ExitStatus{}, err
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		n.mu.Lock()
+		n.killed = true
+		n.mu.Unlock()
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return ExitStatus{}, err
+		}
+		<-done
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.last, nil
+}
+
+// remove marks the node as removed, cancelling a pending automatic restart,
+// unless it owns a geth process. A removed node is never started again.
+func (n *Node) remove() error {
+	n.mu.Lock()
+	if n.state.Active() {
+		n.mu.Unlock()
+		return ErrNodeActive
+	}
+	n.removed = true
+	cancelled := n.cancelRestart()
+	if cancelled {
+		n.state = Stopped
+	}
+	n.mu.Unlock()
+	if cancelled {
+		n.notify()
+	}
+	return nil
+}
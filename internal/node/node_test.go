@@ -0,0 +1,226 @@
+package node
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGeth writes a shell script standing in for geth and returns its path.
+func fakeGeth(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake geth scripts need a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "geth")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitState(t *testing.T, n *Node, want State) {
+	t.Helper()
+	waitFor(t, "state "+want.String(), func() bool { return n.State() == want })
+}
+
+// readyLine is geth's IPC ready line in its terminal format.
+const readyLine = "echo 'INFO [10-18|12:00:00.000] IPC endpoint opened                      url=/tmp/geth.ipc' >&2"
+
+// recordStates returns the states n reports from now on, without repeats.
+func recordStates(n *Node) func() []State {
+	var (
+		mu     sync.Mutex
+		states []State
+	)
+	n.AddStateListener(func(s State) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(states) == 0 || states[len(states)-1] != s {
+			states = append(states, s)
+		}
+	})
+	return func() []State {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]State(nil), states...)
+	}
+}
+
+func TestStopClean(t *testing.T) {
+	exits := make(chan ExitStatus, 1)
+	n := &Node{
+		GethFileLocation: fakeGeth(t, "trap 'exit 0' INT\n"+readyLine+"\nwhile :; do sleep 0.05; done"),
+		StopTimeout:      5 * time.Second,
+		OnExit:           func(s ExitStatus) { exits <- s },
+	}
+	states := recordStates(n)
+	if err := n.Start(Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, n, Ready)
+	if !n.Running() {
+		t.Error("Running = false for a ready node")
+	}
+	if err := n.Start(Spec{}); err != ErrAlreadyRunning {
+		t.Errorf("second Start = %v, want ErrAlreadyRunning", err)
+	}
+
+	status, err := n.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ExitCode != 0 || status.Killed || status.ShutdownTime <= 0 || status.Reason != "" {
+		t.Errorf("status = %+v, want a clean exit after SIGINT", status)
+	}
+	if got := <-exits; got.PID != status.PID {
+		t.Errorf("OnExit got pid %d, Stop returned %d", got.PID, status.PID)
+	}
+	if len(status.LastLines) != 1 || !strings.Contains(status.LastLines[0], "IPC endpoint opened") {
+		t.Errorf("LastLines = %q, want the ready line", status.LastLines)
+	}
+	want := []State{Starting, Ready, Stopping, Stopped}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("states = %v, want %v", got, want)
+	}
+	if n.Running() {
+		t.Error("Running = true after Stop")
+	}
+	if _, err := n.Stop(); err != ErrNotRunning {
+		t.Errorf("Stop of a stopped node = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestStopIgnoredSIGINT(t *testing.T) {
+	n := &Node{
+		GethFileLocation: fakeGeth(t, "trap '' INT\n"+readyLine+"\nwhile :; do sleep 0.05; done"),
+		StopTimeout:      200 * time.Millisecond,
+	}
+	if err := n.Start(Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, n, Ready)
+
+	status, err := n.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Killed || status.ExitCode != -1 || status.State != "signal: killed" {
+		t.Errorf("status = %+v, want geth killed after the stop timeout", status)
+	}
+	if status.ShutdownTime < 200*time.Millisecond {
+		t.Errorf("ShutdownTime = %v, want at least the stop timeout", status.ShutdownTime)
+	}
+	if s := n.State(); s != Stopped {
+		t.Errorf("state = %v, want Stopped", s)
+	}
+
+	// the next run starts without the kill flag
+	if err := n.Start(Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, n, Ready)
+	n.mu.Lock()
+	killed := n.killed
+	n.mu.Unlock()
+	if killed {
+		t.Error("killed carried over to the next run")
+	}
+	if status, err := n.Stop(); err != nil || !status.Killed {
+		t.Errorf("Stop = %+v, %v", status, err)
+	}
+}
+
+func TestCrash(t *testing.T) {
+	exits := make(chan ExitStatus, 1)
+	crashes := make(chan CrashReport, 1)
+	n := &Node{
+		GethFileLocation: fakeGeth(t, "echo 'Fatal: Error starting protocol stack: listen tcp :30303: bind: address already in use' >&2\nexit 1"),
+		OnExit:           func(s ExitStatus) { exits <- s },
+		OnCrash:          func(r CrashReport) { crashes <- r },
+	}
+	states := recordStates(n)
+	if err := n.Start(Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	r := <-crashes
+	status := <-exits
+	if status.ExitCode != 1 || status.Killed || status.ShutdownTime != 0 {
+		t.Errorf("status = %+v, want exit code 1 without a stop request", status)
+	}
+	if !strings.Contains(status.Reason, "address already in use") {
+		t.Errorf("Reason = %q, want the Fatal line", status.Reason)
+	}
+	if r.Status.PID != status.PID || r.Backoff != 0 || r.CrashLoop || r.Restarts != 0 {
+		t.Errorf("crash report = %+v, want the exit and no restart", r)
+	}
+	if want := []State{Starting, Crashed}; !reflect.DeepEqual(states(), want) {
+		t.Errorf("states = %v, want %v", states(), want)
+	}
+	if n.RestartPending() {
+		t.Error("restart pending with the never policy")
+	}
+	if _, err := n.Stop(); err != ErrNotRunning {
+		t.Errorf("Stop of a crashed node = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestStartMissingBinary(t *testing.T) {
+	n := &Node{GethFileLocation: filepath.Join(t.TempDir(), "geth")}
+	if err := n.Start(Spec{}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Start = %v, want fs.ErrNotExist", err)
+	}
+	if s := n.State(); s != Stopped {
+		t.Errorf("state = %v, want Stopped", s)
+	}
+}
+
+func TestStopSignalFailure(t *testing.T) {
+	// a released process cannot be signalled
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Release(); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &exec.Cmd{Process: p}
+	n := &Node{state: Ready, cmd: cmd, done: make(chan struct{})}
+	states := recordStates(n)
+
+	if _, err := n.Stop(); err == nil {
+		t.Fatal("Stop succeeded without a process to signal")
+	}
+	if s := n.State(); s != Ready {
+		t.Errorf("state after the failed Stop = %v, want Ready restored", s)
+	}
+	n.mu.Lock()
+	stopping := n.stopping
+	n.mu.Unlock()
+	if !stopping.IsZero() {
+		t.Error("stop request still recorded after the failed Stop")
+	}
+	if want := []State{Stopping, Ready}; !reflect.DeepEqual(states(), want) {
+		t.Errorf("states = %v, want %v", states(), want)
+	}
+}
@@ -0,0 +1,193 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gene/internal/gethlog"
+	"gene/internal/rpc"
+)
+
+// DefaultReadyTimeout is how long a started geth may take to become Ready.
+const DefaultReadyTimeout = time.Minute
+
+const (
+	// readyPollInterval is the delay between two probes of the endpoints
+	readyPollInterval = 500 * time.Millisecond
+	// probeTimeout bounds a single web3_clientVersion call
+	probeTimeout = 2 * time.Second
+)
+
+const (
+	// ipcReadyMessage is logged once the IPC endpoint accepts requests
+	ipcReadyMessage = "IPC endpoint opened"
+	// httpReadyMessage is logged once an HTTP server accepts requests. geth
+	// logs it for the authenticated authrpc server too, which is always on.
+	httpReadyMessage = "HTTP server started"
+)
+
+// ReadyConfig decides when a started geth counts as Ready: once one of its
+// endpoints answers web3_clientVersion, or once it logs that the IPC
+// endpoint opened or, when HTTPURL is set, that the HTTP-RPC server started.
+type ReadyConfig struct {
+	// IPCPath is the IPC socket to probe, empty to skip it
+	IPCPath string
+	// HTTPURL is the HTTP-RPC endpoint to probe, empty to skip it
+	HTTPURL string
+	// Timeout is how long to wait before reporting the node as not ready (default: 1m)
+	Timeout time.Duration
+}
+
+func (c ReadyConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultReadyTimeout
+	}
+	return c.Timeout
+}
+
+// ReadyError is passed to OnNotReady when geth keeps running without
+// becoming Ready within the timeout.
+type ReadyError struct {
+	// PID of the geth process
+	PID int
+	// Timeout is how long GeNe waited
+	Timeout time.Duration
+	// Reason is the log line that most likely explains the failure, if any
+	Reason string
+	// LastLines are the last lines geth wrote to stdout and stderr
+	LastLines []string
+}
+
+func (e *ReadyError) Error() string {
+	msg := fmt.Sprintf("geth (pid %d) is not ready after %s", e.PID, e.Timeout)
+	if e.Reason != "" {
+		msg += "; likely cause: " + e.Reason
+	}
+	return msg
+}
+
+// awaitReady moves the node from Starting to Ready once geth is ready, and
+// reports a ReadyError if that does not happen within the timeout. It gives
+// up silently when the process exits first, since wait reports that.
+func (n *Node) awaitReady(cmd *exec.Cmd, out output, done chan struct{}, cfg ReadyConfig) {
+	seen := make(chan struct{}, 1)
+	signal := func(l gethlog.Line) {
+		if isReadyLine(cfg, l) {
+			select {
+			case seen <- struct{}{}:
+			default:
+			}
+		}
+	}
+	cancel := out.logs.Subscribe(func(l gethlog.Line) {
+		if l.Seq > out.startSeq {
+			signal(l)
+		}
+	})
+	defer cancel()
+	// lines written before the subscription
+	for _, l := range out.logs.Since(out.startSeq) {
+		signal(l)
+	}
+
+	timeout := time.NewTimer(cfg.timeout())
+	defer timeout.Stop()
+	poll := time.NewTicker(readyPollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-seen:
+			n.markReady(cmd)
+			return
+		case <-poll.C:
+			if probe(cfg) == nil {
+				n.markReady(cmd)
+				return
+			}
+		case <-timeout.C:
+			n.mu.Lock()
+			starting := n.cmd == cmd && n.state == Starting
+			onNotReady := n.OnNotReady
+			n.mu.Unlock()
+			if starting && onNotReady != nil {
+				onNotReady(&ReadyError{
+					PID:       cmd.Process.Pid,
+					Timeout:   cfg.timeout(),
+					Reason:    gethlog.FatalReason(out.logs.Since(out.startSeq)),
+					LastLines: out.tail(DefaultTailLines),
+				})
+			}
+			return
+		}
+	}
+}
+
+// markReady moves the node to Ready unless cmd has exited or is being stopped.
+func (n *Node) markReady(cmd *exec.Cmd) {
+	n.mu.Lock()
+	if n.cmd != cmd || n.state != Starting {
+		n.mu.Unlock()
+		return
+	}
+	n.state = Ready
+	n.mu.Unlock()
+	n.notify()
+}
+
+// isReadyLine reports whether l says an endpoint GeNe can reach accepts
+// requests. The HTTP line only counts when the HTTP-RPC server is enabled,
+// and never for the authrpc server, which logs it with auth=true.
+func isReadyLine(cfg ReadyConfig, l gethlog.Line) bool {
+	msg := l.Text
+	if l.Event != nil {
+		msg = l.Event.Message
+	}
+	switch {
+	case strings.HasPrefix(msg, ipcReadyMessage):
+		return true
+	case strings.HasPrefix(msg, httpReadyMessage):
+		if cfg.HTTPURL == "" {
+			return false
+		}
+		if l.Event == nil {
+			return !strings.Contains(l.Text, "auth=true")
+		}
+		auth, _ := l.Event.Get("auth")
+		return auth != "true"
+	}
+	return false
+}
+
+// probe calls web3_clientVersion on the configured endpoints and returns nil
+// as soon as one of them answers.
+func probe(cfg ReadyConfig) error {
+	err := errors.New("no endpoint to probe")
+	for _, endpoint := range []string{cfg.IPCPath, cfg.HTTPURL} {
+		if endpoint == "" {
+			continue
+		}
+		if err = probeEndpoint(endpoint); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func probeEndpoint(endpoint string) error {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	_, err = client.ClientVersion(ctx)
+	return err
+}
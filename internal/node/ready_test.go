@@ -0,0 +1,134 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gene/internal/gethlog"
+)
+
+const (
+	authRPCLine = "INFO [10-18|12:00:00.000] HTTP server started                      endpoint=127.0.0.1:8551 auth=true prefix= cors=localhost vhosts=localhost"
+	httpRPCLine = "INFO [10-18|12:00:00.001] HTTP server started                      endpoint=127.0.0.1:8545 auth=false prefix= cors= vhosts=localhost"
+	ipcLine     = "INFO [10-18|12:00:00.002] IPC endpoint opened                      url=/tmp/geth.ipc"
+)
+
+func TestIsReadyLine(t *testing.T) {
+	httpOn := ReadyConfig{HTTPURL: "http://127.0.0.1:8545"}
+	tests := []struct {
+		name string
+		cfg  ReadyConfig
+		text string
+		want bool
+	}{
+		{"ipc", ReadyConfig{}, ipcLine, true},
+		{"http enabled", httpOn, httpRPCLine, true},
+		{"http disabled", ReadyConfig{}, httpRPCLine, false},
+		{"authrpc", httpOn, authRPCLine, false},
+		{"authrpc http disabled", ReadyConfig{}, authRPCLine, false},
+		{"json http", httpOn, `{"auth":false,"endpoint":"127.0.0.1:8545","lvl":"info","msg":"HTTP server started","t":"2026-10-18T12:00:00.001+00:00"}`, true},
+		{"json authrpc", httpOn, `{"auth":true,"endpoint":"127.0.0.1:8551","lvl":"info","msg":"HTTP server started","t":"2026-10-18T12:00:00.000+00:00"}`, false},
+		{"other message", httpOn, "INFO [10-18|12:00:00.000] HTTP server stopped                      endpoint=127.0.0.1:8545", false},
+		{"unparsed ipc", ReadyConfig{}, "IPC endpoint opened", true},
+		{"unparsed authrpc", httpOn, "HTTP server started endpoint=127.0.0.1:8551 auth=true", false},
+	}
+	buf := gethlog.NewBuffer(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReadyLine(tt.cfg, buf.Append(gethlog.Stderr, tt.text)); got != tt.want {
+				t.Errorf("isReadyLine = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// versionServer answers web3_clientVersion like geth's HTTP-RPC server.
+func versionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "web3_clientVersion" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "Geth/v1.10.26-stable/linux-amd64/go1.19"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbe(t *testing.T) {
+	srv := versionServer(t)
+	if err := probe(ReadyConfig{HTTPURL: srv.URL}); err != nil {
+		t.Errorf("probe of an answering endpoint: %v", err)
+	}
+	if err := probe(ReadyConfig{IPCPath: t.TempDir() + "/geth.ipc", HTTPURL: srv.URL}); err != nil {
+		t.Errorf("probe falling back to HTTP: %v", err)
+	}
+	if err := probe(ReadyConfig{}); err == nil {
+		t.Error("probe without endpoints succeeded")
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if err := probe(ReadyConfig{HTTPURL: closed.URL}); err == nil {
+		t.Error("probe of a closed endpoint succeeded")
+	}
+}
+
+// silentGeth keeps running without logging anything until it gets SIGINT.
+const silentGeth = "trap 'exit 0' INT\nwhile :; do sleep 0.05; done"
+
+func TestAwaitReadyLogLine(t *testing.T) {
+	n := &Node{GethFileLocation: fakeGeth(t, "trap 'exit 0' INT\necho '"+authRPCLine+"' >&2\necho '"+httpRPCLine+"' >&2\nwhile :; do sleep 0.05; done")}
+	// nothing listens there, only the log line can make the node ready
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if err := n.Start(Spec{Ready: ReadyConfig{HTTPURL: closed.URL}}); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+	waitState(t, n, Ready)
+}
+
+func TestAwaitReadyProbe(t *testing.T) {
+	n := &Node{GethFileLocation: fakeGeth(t, silentGeth)}
+	if err := n.Start(Spec{Ready: ReadyConfig{HTTPURL: versionServer(t).URL}}); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+	waitState(t, n, Ready)
+}
+
+func TestAwaitReadyTimeout(t *testing.T) {
+	notReady := make(chan *ReadyError, 1)
+	n := &Node{
+		// only the authrpc server comes up, HTTP-RPC is disabled
+		GethFileLocation: fakeGeth(t, "trap 'exit 0' INT\necho '"+authRPCLine+"' >&2\nwhile :; do sleep 0.05; done"),
+		OnNotReady:       func(e *ReadyError) { notReady <- e },
+	}
+	if err := n.Start(Spec{Ready: ReadyConfig{Timeout: 300 * time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	select {
+	case e := <-notReady:
+		if e.Timeout != 300*time.Millisecond || e.PID == 0 {
+			t.Errorf("ReadyError = %+v", e)
+		}
+		if len(e.LastLines) != 1 || e.LastLines[0] != authRPCLine {
+			t.Errorf("LastLines = %q, want the authrpc line", e.LastLines)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnNotReady was not called")
+	}
+	if s := n.State(); s != Starting {
+		t.Errorf("state = %v, want Starting", s)
+	}
+}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrDuplicateNode is returned by Registry.Add when the name is taken.
+	ErrDuplicateNode = errors.New("a node with that name already exists")
+	// ErrUnknownNode is returned when the registry has no node with the name.
+	ErrUnknownNode = errors.New("no node with that name")
+	// ErrNodeActive is returned by Registry.Remove while the node owns a geth process.
+	ErrNodeActive = errors.New("node is still running")
+)
+
+// Registry holds the nodes GeNe manages, each with its own process, logs
+// and state, in the order they were added.
+type Registry struct {
+	mu    sync.Mutex
+	names []string
+	nodes map[string]*Node
+}
+
+// Add registers n under name.
+func (r *Registry) Add(name string, n *Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nodes[name]; ok {
+		return fmt.Errorf("%q: %w", name, ErrDuplicateNode)
+	}
+	if r.nodes == nil {
+		r.nodes = make(map[string]*Node)
+	}
+	r.nodes[name] = n
+	r.names = append(r.names, name)
+	return nil
+}
+
+// Remove unregisters the node called name and cancels its pending automatic
+// restart, if any, so it never starts a geth GeNe no longer shows. Running
+// nodes have to be stopped first.
+func (r *Registry) Remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.nodes[name]
+	if !ok {
+		return fmt.Errorf("%q: %w", name, ErrUnknownNode)
+	}
+	if err := n.remove(); err != nil {
+		return fmt.Errorf("%q: %w", name, err)
+	}
+	delete(r.nodes, name)
+	for i, v := range r.names {
+		if v == name {
+			r.names = append(r.names[:i], r.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Get returns the node called name.
+func (r *Registry) Get(name string) (*Node, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.nodes[name]
+	return n, ok
+}
+
+// Names returns the names of the registered nodes in the order they were added.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// StopAll stops every running node concurrently and waits for them to exit.
+func (r *Registry) StopAll() map[string]error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	for _, name := range r.Names() {
+		n, _ := r.Get(name)
+		wg.Add(1)
+		go func(name string, n *Node) {
+			defer wg.Done()
+			if _, err := n.Stop(); err != nil && err != ErrNotRunning {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name, n)
+	}
+	wg.Wait()
+	return errs
+}
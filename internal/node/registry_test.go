@@ -0,0 +1,63 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryRemoveCrashed(t *testing.T) {
+	n := &Node{GethFileLocation: fakeGeth(t, "exit 1")}
+	var r Registry
+	if err := r.Add("crashy", n); err != nil {
+		t.Fatal(err)
+	}
+	spec := Spec{Restart: RestartConfig{Policy: RestartAlways, InitialBackoff: 100 * time.Millisecond}}
+	if err := n.Start(spec); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "a pending restart", func() bool {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return n.state == Crashed && n.restartTimer != nil
+	})
+
+	if err := r.Remove("crashy"); err != nil {
+		t.Fatalf("Remove of a crashed node: %v", err)
+	}
+	if _, ok := r.Get("crashy"); ok {
+		t.Error("removed node is still registered")
+	}
+	if s := n.State(); s != Stopped {
+		t.Errorf("state after Remove = %v, want Stopped", s)
+	}
+
+	// the cancelled restart must never start geth again
+	time.Sleep(300 * time.Millisecond)
+	if s := n.State(); s != Stopped {
+		t.Errorf("state after the restart delay = %v, want Stopped", s)
+	}
+	if err := n.Start(spec); !errors.Is(err, ErrNodeRemoved) {
+		t.Errorf("Start after Remove = %v, want ErrNodeRemoved", err)
+	}
+}
+
+func TestRegistryRemoveActive(t *testing.T) {
+	n := &Node{GethFileLocation: fakeGeth(t, "trap 'exit 0' INT\nwhile :; do sleep 0.05; done"), StopTimeout: 5 * time.Second}
+	var r Registry
+	if err := r.Add("busy", n); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Start(Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove("busy"); !errors.Is(err, ErrNodeActive) {
+		t.Errorf("Remove of a running node = %v, want ErrNodeActive", err)
+	}
+	if _, err := n.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove("busy"); err != nil {
+		t.Errorf("Remove of a stopped node: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RestartPolicy decides whether a crashed geth is started again.
+type RestartPolicy string
+
+const (
+	// RestartNever leaves a crashed node in the Crashed state
+	RestartNever RestartPolicy = "never"
+	// RestartOnFailure restarts geth only when it exits with a non-zero code or a signal
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways restarts geth whenever it exits without being asked to
+	RestartAlways RestartPolicy = "always"
+)
+
+// RestartPolicies lists the supported policies in the order they are offered to the user.
+var RestartPolicies = []RestartPolicy{RestartNever, RestartOnFailure, RestartAlways}
+
+// ParseRestartPolicy converts s into a RestartPolicy. An empty string means RestartNever.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	if s == "" {
+		return RestartNever, nil
+	}
+	for _, p := range RestartPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown restart policy %q", s)
+}
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultMaxRestarts    = 5
+	defaultRestartWindow  = 10 * time.Minute
+)
+
+// RestartConfig configures automatic restarts of a crashed node.
+// Zero values fall back to the defaults noted on each field.
+type RestartConfig struct {
+	// Policy decides which exits trigger a restart (default: never)
+	Policy RestartPolicy
+	// InitialBackoff is the delay before the first restart, doubled for every further restart (default: 1s)
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between restarts (default: 1m)
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of restarts allowed within Window before giving up (default: 5)
+	MaxRestarts int
+	// Window is the period over which restarts are counted (default: 10m)
+	Window time.Duration
+}
+
+func (c RestartConfig) shouldRestart(status ExitStatus) bool {
+	switch c.Policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return status.ExitCode != 0
+	default:
+		return false
+	}
+}
+
+func (c RestartConfig) maxRestarts() int {
+	if c.MaxRestarts <= 0 {
+		return defaultMaxRestarts
+	}
+	return c.MaxRestarts
+}
+
+func (c RestartConfig) window() time.Duration {
+	if c.Window <= 0 {
+		return defaultRestartWindow
+	}
+	return c.Window
+}
+
+// backoff returns the delay before restart number attempt, counting from zero.
+func (c RestartConfig) backoff(attempt int) time.Duration {
+	d, max := c.InitialBackoff, c.MaxBackoff
+	if d <= 0 {
+		d = defaultInitialBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// CrashReport describes an unexpected exit and what the restart policy did about it.
+type CrashReport struct {
+	// Status is how the process exited, including its last output lines
+	Status ExitStatus
+	// Restarts is the number of restarts already made within the restart window
+	Restarts int
+	// Backoff is the delay before the next restart, zero if no restart is scheduled
+	Backoff time.Duration
+	// CrashLoop is true when the restart limit was hit and the node was left Crashed
+	CrashLoop bool
+	// RestartErr is set when a scheduled restart could not launch geth
+	RestartErr error
+}
+
+func (r CrashReport) String() string {
+	msg := fmt.Sprintf("geth (pid %d) crashed: %s", r.Status.PID, r.Status.State)
+	if r.Status.Reason != "" {
+		msg += "; likely cause: " + r.Status.Reason
+	}
+	switch {
+	case r.RestartErr != nil:
+		msg += fmt.Sprintf("; restart failed: %s", r.RestartErr)
+	case r.CrashLoop:
+		msg += fmt.Sprintf("; crash loop, gave up after %d restarts", r.Restarts)
+	case r.Backoff > 0:
+		msg += fmt.Sprintf("; restarting in %s (restart %d)", r.Backoff, r.Restarts+1)
+	}
+	return msg
+}
+
+// planRestart applies the restart policy to an unexpected exit and, if
+// allowed, schedules the restart. It must be called with n.mu held.
+func (n *Node) planRestart(status ExitStatus) CrashReport {
+	report := CrashReport{Status: status}
+	policy := n.spec.Restart
+	if !policy.shouldRestart(status) {
+		return report
+	}
+
+	// forget restarts that fell out of the window
+	now := time.Now()
+	recent := n.restarts[:0]
+	for _, t := range n.restarts {
+		if now.Sub(t) < policy.window() {
+			recent = append(recent, t)
+		}
+	}
+	n.restarts = recent
+	report.Restarts = len(recent)
+
+	if len(recent) >= policy.maxRestarts() {
+		report.CrashLoop = true
+		return report
+	}
+
+	report.Backoff = policy.backoff(len(recent))
+	n.restarts = append(n.restarts, now)
+	spec := n.spec
+	var timer *time.Timer
+	timer = time.AfterFunc(report.Backoff, func() {
+		n.mu.Lock()
+		if n.restartTimer != timer {
+			// cancelled by Start or Stop
+			n.mu.Unlock()
+			return
+		}
+		n.restartTimer = nil
+		n.mu.Unlock()
+
+		if err := n.start(spec); err != nil {
+			if errors.Is(err, ErrNodeRemoved) {
+				return
+			}
+			n.mu.Lock()
+			n.state = Crashed
+			n.mu.Unlock()
+			n.notify()
+			n.reportCrash(CrashReport{Status: status, Restarts: report.Restarts + 1, RestartErr: err})
+		}
+	})
+	n.restartTimer = timer
+
+	return report
+}
+
+// RestartPending reports whether the node crashed and an automatic restart
+// is scheduled. Stop cancels it.
+func (n *Node) RestartPending() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.restartTimer != nil
+}
+
+// cancelRestart stops a pending automatic restart. It must be called with n.mu held.
+func (n *Node) cancelRestart() bool {
+	if n.restartTimer == nil {
+		return false
+	}
+	n.restartTimer.Stop()
+	n.restartTimer = nil
+	return true
+}
+
+func (n *Node) reportCrash(r CrashReport) {
+	n.mu.Lock()
+	onCrash := n.OnCrash
+	n.mu.Unlock()
+	if onCrash != nil {
+		onCrash(r)
+	}
+}
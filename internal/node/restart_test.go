@@ -0,0 +1,172 @@
+package node
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RestartConfig
+		attempt int
+		want    time.Duration
+	}{
+		{"defaults first", RestartConfig{}, 0, time.Second},
+		{"defaults doubled", RestartConfig{}, 3, 8 * time.Second},
+		{"defaults capped", RestartConfig{}, 10, time.Minute},
+		{"initial", RestartConfig{InitialBackoff: 3 * time.Second}, 0, 3 * time.Second},
+		{"doubled", RestartConfig{InitialBackoff: 3 * time.Second}, 2, 12 * time.Second},
+		{"capped", RestartConfig{InitialBackoff: 3 * time.Second, MaxBackoff: 10 * time.Second}, 2, 10 * time.Second},
+		{"initial above max", RestartConfig{InitialBackoff: time.Hour, MaxBackoff: time.Minute}, 0, time.Minute},
+		{"no overflow", RestartConfig{InitialBackoff: time.Second, MaxBackoff: time.Hour}, 1000, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.backoff(tt.attempt); got != tt.want {
+				t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	tests := []struct {
+		policy   RestartPolicy
+		exitCode int
+		want     bool
+	}{
+		{RestartNever, 1, false},
+		{RestartOnFailure, 0, false},
+		{RestartOnFailure, 1, true},
+		{RestartOnFailure, -1, true},
+		{RestartAlways, 0, true},
+		{RestartAlways, 2, true},
+	}
+	for _, tt := range tests {
+		c := RestartConfig{Policy: tt.policy}
+		if got := c.shouldRestart(ExitStatus{ExitCode: tt.exitCode}); got != tt.want {
+			t.Errorf("%s with exit code %d: shouldRestart = %v, want %v", tt.policy, tt.exitCode, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRestartWindow(t *testing.T) {
+	now := time.Now()
+	n := &Node{spec: Spec{Restart: RestartConfig{
+		Policy:         RestartAlways,
+		InitialBackoff: time.Hour,
+		MaxRestarts:    2,
+		Window:         time.Minute,
+	}}}
+
+	// restarts older than the window are forgotten
+	n.mu.Lock()
+	n.restarts = []time.Time{now.Add(-3 * time.Minute), now.Add(-2 * time.Minute), now.Add(-10 * time.Second)}
+	r := n.planRestart(ExitStatus{ExitCode: 1})
+	n.cancelRestart()
+	n.mu.Unlock()
+	if r.CrashLoop || r.Restarts != 1 || r.Backoff != time.Minute {
+		t.Errorf("report = %+v, want 1 restart in the window and the backoff capped at 1m", r)
+	}
+
+	// MaxRestarts within the window is a crash loop
+	n.mu.Lock()
+	r = n.planRestart(ExitStatus{ExitCode: 1})
+	pending := n.restartTimer != nil
+	n.mu.Unlock()
+	if !r.CrashLoop || r.Restarts != 2 || r.Backoff != 0 || pending {
+		t.Errorf("report = %+v, pending %v, want a crash loop after 2 restarts and nothing scheduled", r, pending)
+	}
+}
+
+func TestCrashLoop(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		reports []CrashReport
+		starts  int
+	)
+	n := &Node{
+		GethFileLocation: fakeGeth(t, "echo 'Fatal: Failed to register the Ethereum service: incompatible genesis'\nexit 1"),
+		OnCrash: func(r CrashReport) {
+			mu.Lock()
+			reports = append(reports, r)
+			mu.Unlock()
+		},
+	}
+	n.AddStateListener(func(s State) {
+		if s == Starting {
+			mu.Lock()
+			starts++
+			mu.Unlock()
+		}
+	})
+	spec := Spec{Restart: RestartConfig{
+		Policy:         RestartOnFailure,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxRestarts:    3,
+		Window:         time.Minute,
+	}}
+	if err := n.Start(spec); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the crash loop", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reports) > 0 && reports[len(reports)-1].CrashLoop
+	})
+
+	// no restart follows the crash loop
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 4 {
+		t.Fatalf("got %d crash reports, want the first crash, 3 restarts crashing and no more", len(reports))
+	}
+	for i, r := range reports {
+		if r.Restarts != i {
+			t.Errorf("report %d: Restarts = %d, want %d", i, r.Restarts, i)
+		}
+		if r.Status.Reason == "" {
+			t.Errorf("report %d: no crash reason", i)
+		}
+		if i < 3 && (r.CrashLoop || r.Backoff == 0) {
+			t.Errorf("report %d = %+v, want a scheduled restart", i, r)
+		}
+	}
+	if s := n.State(); s != Crashed {
+		t.Errorf("state = %v, want Crashed", s)
+	}
+	if n.RestartPending() {
+		t.Error("a restart is pending after the crash loop")
+	}
+}
+
+func TestStopCancelsRestart(t *testing.T) {
+	n := &Node{GethFileLocation: fakeGeth(t, "exit 3")}
+	if err := n.Start(Spec{Restart: RestartConfig{Policy: RestartAlways, InitialBackoff: time.Hour}}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "a pending restart", n.RestartPending)
+	if s := n.State(); s != Crashed {
+		t.Errorf("state with a pending restart = %v, want Crashed", s)
+	}
+
+	status, err := n.Stop()
+	if err != nil {
+		t.Fatalf("Stop with a pending restart: %v", err)
+	}
+	if status.ExitCode != 3 {
+		t.Errorf("Stop returned exit code %d, want the crash's 3", status.ExitCode)
+	}
+	if n.RestartPending() {
+		t.Error("restart still pending after Stop")
+	}
+	if s := n.State(); s != Stopped {
+		t.Errorf("state after Stop = %v, want Stopped", s)
+	}
+	if _, err := n.Stop(); err != ErrNotRunning {
+		t.Errorf("second Stop = %v, want ErrNotRunning", err)
+	}
+}
@@ -0,0 +1,69 @@
+package node
+
+// State is the lifecycle state of a Node.
+type State int
+
+const (
+	// Stopped means no geth process is running
+	Stopped State = iota
+	// Starting means geth is being launched
+	Starting
+	// Ready means geth is running and answers on its endpoints
+	Ready
+	// Stopping means geth has been asked to shut down and has not exited yet
+	Stopping
+	// Crashed means geth exited without being asked to
+	Crashed
+)
+
+func (s State) String() string {
+	switch s {
+	case Stopped:
+		return "Stopped"
+	case Starting:
+		return "Starting"
+	case Ready:
+		return "Ready"
+	case Stopping:
+		return "Stopping"
+	case Crashed:
+		return "Crashed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Active reports whether a geth process is, or is about to be, running.
+func (s State) Active() bool {
+	return s == Starting || s == Ready || s == Stopping
+}
+
+// State returns the current lifecycle state of the node.
+func (n *Node) State() State {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.state
+}
+
+// AddStateListener registers fn to be called with every state change.
+// Listeners are called synchronously and must not block.
+func (n *Node) AddStateListener(fn func(State)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners = append(n.listeners, fn)
+}
+
+// notify passes the current state to the listeners. State changes are made
+// under n.mu and followed by notify once the lock is released, so listeners
+// always end up seeing the latest state even when transitions race.
+func (n *Node) notify() {
+	n.mu.Lock()
+	s := n.state
+	listeners := make([]func(State), len(n.listeners))
+	copy(listeners, n.listeners)
+	n.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(s)
+	}
+}
@@ -0,0 +1,83 @@
+package ports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpListen is the socket state of a listening TCP socket in /proc/net/tcp.
+const tcpListen = "0A"
+
+// FindOwner returns the process listening on the TCP port, found through
+// /proc/net/tcp and the socket links in /proc/<pid>/fd. Sockets of other
+// users' processes cannot be matched without privileges.
+func FindOwner(port int) (Owner, error) {
+	inodes := make(map[string]bool)
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		if err := listeningInodes(file, port, inodes); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Owner{}, err
+		}
+	}
+	if len(inodes) == 0 {
+		return Owner{}, fmt.Errorf("no listening socket on port %d", port)
+	}
+
+	procs, err := filepath.Glob("/proc/[0-9]*/fd/*")
+	if err != nil {
+		return Owner{}, err
+	}
+	for _, fd := range procs {
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		if !inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] {
+			continue
+		}
+		dir := filepath.Dir(filepath.Dir(fd))
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+		return Owner{PID: pid, Command: strings.TrimSpace(string(comm))}, nil
+	}
+	return Owner{}, fmt.Errorf("owner of port %d not found", port)
+}
+
+// listeningInodes adds the inodes of the sockets listening on port in a
+// /proc/net/tcp style file:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:2161 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 123456 ...
+func listeningInodes(file string, port int, inodes map[string]bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Scan() // header
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+		i := strings.LastIndexByte(fields[1], ':')
+		if i < 0 {
+			continue
+		}
+		p, err := strconv.ParseUint(fields[1][i+1:], 16, 16)
+		if err != nil || int(p) != port {
+			continue
+		}
+		inodes[fields[9]] = true
+	}
+	return s.Err()
+}
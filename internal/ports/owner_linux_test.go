@@ -0,0 +1,67 @@
+package ports
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestListeningInodes(t *testing.T) {
+	tests := []struct {
+		file string
+		port int
+		want map[string]bool
+	}{
+		{"testdata/tcp", 8545, map[string]bool{"123456": true}},
+		{"testdata/tcp", 30303, map[string]bool{"234567": true}},
+		{"testdata/tcp", 8551, map[string]bool{"567890": true}},
+		// 41394 only appears as a connected, not listening, socket
+		{"testdata/tcp", 41394, map[string]bool{}},
+		{"testdata/tcp", 9999, map[string]bool{}},
+		{"testdata/tcp6", 30303, map[string]bool{"789012": true}},
+		{"testdata/tcp6", 8545, map[string]bool{"890123": true}},
+	}
+	for _, tt := range tests {
+		inodes := make(map[string]bool)
+		if err := listeningInodes(tt.file, tt.port, inodes); err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if !reflect.DeepEqual(inodes, tt.want) {
+			t.Errorf("%s port %d: inodes = %v, want %v", tt.file, tt.port, inodes, tt.want)
+		}
+	}
+
+	// both files add to the same set, as FindOwner reads them
+	inodes := make(map[string]bool)
+	for _, file := range []string{"testdata/tcp", "testdata/tcp6"} {
+		if err := listeningInodes(file, 30303, inodes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := map[string]bool{"234567": true, "789012": true}; !reflect.DeepEqual(inodes, want) {
+		t.Errorf("inodes of both files = %v, want %v", inodes, want)
+	}
+
+	if err := listeningInodes("testdata/missing", 8545, inodes); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestFindOwner(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	owner, err := FindOwner(l.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner.PID != os.Getpid() || owner.Command == "" {
+		t.Errorf("owner = %+v, want this test process (pid %d)", owner, os.Getpid())
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package ports
+
+import "errors"
+
+// FindOwner is only implemented on Linux, where /proc/net/tcp is available.
+func FindOwner(port int) (Owner, error) {
+	return Owner{}, errors.New("finding the owner of a port is not supported on this platform")
+}
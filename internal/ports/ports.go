@@ -0,0 +1,150 @@
+// Package ports checks that the ports geth is about to listen on are free,
+// reports who holds them when they are not and finds free ones.
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"gene/internal/config"
+)
+
+// BlockStep is the distance between the blocks of ports MoveToFreeBlock tries.
+const BlockStep = 10
+
+// maxBlocks bounds how far MoveToFreeBlock looks for a free block.
+const maxBlocks = 100
+
+// ErrNoFreeBlock is returned by MoveToFreeBlock when every block it tried had a busy port.
+var ErrNoFreeBlock = errors.New("no free block of ports found")
+
+// Owner is the process listening on a port.
+type Owner struct {
+	PID int
+	// Command is the process name, e.g. "geth"
+	Command string
+}
+
+func (o Owner) String() string {
+	return fmt.Sprintf("%s (pid %d)", o.Command, o.PID)
+}
+
+// Busy is a port geth needs that is already taken.
+type Busy struct {
+	config.Port
+	// Err is the error returned when trying to listen on the port
+	Err error
+	// Owner is the process holding the port, nil if it could not be found
+	Owner *Owner
+}
+
+func (b Busy) String() string {
+	host := b.Host
+	if host == "" {
+		host = "all interfaces"
+	}
+	msg := fmt.Sprintf("%s %d on %s is in use", b.Field, b.Port.Port, host)
+	if b.Owner != nil {
+		msg += " by " + b.Owner.String()
+	}
+	return msg
+}
+
+// BusyError lists the busy ports found by Check.
+type BusyError []Busy
+
+func (e BusyError) Error() string {
+	msgs := make([]string, len(e))
+	for i, b := range e {
+		msgs[i] = b.String()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Available reports whether geth could listen on port on host. The P2P
+// port is also used for UDP discovery, so udp checks that as well.
+func Available(host string, port int, udp bool) error {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	l.Close()
+	if udp {
+		c, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return err
+		}
+		c.Close()
+	}
+	return nil
+}
+
+// Check returns the ports geth would listen on for cfg that are already
+// taken, or nil when they are all free.
+func Check(cfg config.UserInputForNodeConfig) error {
+	var busy BusyError
+	for _, p := range config.ListenPorts(cfg) {
+		err := Available(p.Host, p.Port, p.Field == "P2PPort")
+		if err == nil {
+			continue
+		}
+		b := Busy{Port: p, Err: err}
+		if owner, err := FindOwner(p.Port); err == nil {
+			b.Owner = &owner
+		}
+		busy = append(busy, b)
+	}
+	if len(busy) == 0 {
+		return nil
+	}
+	return busy
+}
+
+// MoveToFreeBlock shifts every port of cfg by the same multiple of
+// BlockStep until all of them are free and none is in reserved, e.g. the
+// ports of other nodes. The returned config has every port field set.
+func MoveToFreeBlock(cfg config.UserInputForNodeConfig, reserved map[int]bool) (config.UserInputForNodeConfig, error) {
+	base := make(map[string]int)
+	all := cfg
+	all.HTTPEnabled, all.WSEnabled = true, true
+	for _, p := range config.ListenPorts(all) {
+		base[p.Field] = p.Port
+	}
+
+	for i := 1; i <= maxBlocks; i++ {
+		moved := cfg
+		fields := map[string]*string{
+			"RPCHTTPPort":   &moved.RPCHTTPPort,
+			"WSRPCHTTPPort": &moved.WSRPCHTTPPort,
+			"AdminPort":     &moved.AdminPort,
+			"P2PPort":       &moved.P2PPort,
+		}
+		free := true
+		for field, value := range fields {
+			port := base[field] + i*BlockStep
+			// ListenPorts leaves out invalid ports rather than reporting them
+			if port > 65535 {
+				free = false
+			}
+			*value = strconv.Itoa(port)
+		}
+		if !free {
+			break
+		}
+
+		for _, p := range config.ListenPorts(moved) {
+			if reserved[p.Port] || Available(p.Host, p.Port, p.Field == "P2PPort") != nil {
+				free = false
+				break
+			}
+		}
+		if free {
+			return moved, nil
+		}
+	}
+	return cfg, ErrNoFreeBlock
+}
@@ -0,0 +1,126 @@
+package ports
+
+import (
+	"errors"
+	"net"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"gene/internal/config"
+)
+
+// hold listens on a free TCP port of 127.0.0.1 until the test ends.
+func hold(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a TCP port of 127.0.0.1 that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// localConfig enables HTTP on 127.0.0.1 with every port set.
+func localConfig(httpPort, wsPort, authPort, p2pPort int) config.UserInputForNodeConfig {
+	return config.UserInputForNodeConfig{
+		HTTPEnabled:   true,
+		HTTPAddr:      "127.0.0.1",
+		RPCHTTPPort:   strconv.Itoa(httpPort),
+		WSRPCHTTPPort: strconv.Itoa(wsPort),
+		AdminAddr:     "127.0.0.1",
+		AdminPort:     strconv.Itoa(authPort),
+		P2PPort:       strconv.Itoa(p2pPort),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	held := hold(t)
+	cfg := localConfig(held, freePort(t), freePort(t), freePort(t))
+
+	err := Check(cfg)
+	var busy BusyError
+	if !errors.As(err, &busy) {
+		t.Fatalf("Check = %v, want a BusyError", err)
+	}
+	if len(busy) != 1 || busy[0].Field != "RPCHTTPPort" || busy[0].Port.Port != held || busy[0].Host != "127.0.0.1" {
+		t.Fatalf("busy = %+v, want only RPCHTTPPort %d", busy, held)
+	}
+	if busy[0].Err == nil {
+		t.Error("Busy has no listen error")
+	}
+	if runtime.GOOS == "linux" && (busy[0].Owner == nil || busy[0].Owner.PID != os.Getpid()) {
+		t.Errorf("owner = %v, want this test process", busy[0].Owner)
+	}
+
+	// the WS port is only checked when WS is enabled
+	cfg = localConfig(freePort(t), held, freePort(t), freePort(t))
+	if err := Check(cfg); err != nil {
+		t.Errorf("Check with the busy port on disabled WS = %v", err)
+	}
+	cfg.WSEnabled = true
+	cfg.WSRPCInterface = "127.0.0.1"
+	if err := Check(cfg); err == nil {
+		t.Error("Check with the busy port on enabled WS succeeded")
+	}
+}
+
+func TestMoveToFreeBlock(t *testing.T) {
+	held := hold(t)
+	cfg := localConfig(held, freePort(t), freePort(t), freePort(t))
+	base := config.ListenPorts(cfg)
+
+	// the first block is reserved by another node
+	reserved := map[int]bool{held + BlockStep: true}
+	moved, err := MoveToFreeBlock(cfg, reserved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(moved); err != nil {
+		t.Errorf("moved ports are busy: %v", err)
+	}
+	ports := config.ListenPorts(moved)
+	if len(ports) != len(base) {
+		t.Fatalf("moved ports = %+v, want as many as %+v", ports, base)
+	}
+	shift := ports[0].Port - base[0].Port
+	if shift < 2*BlockStep || shift%BlockStep != 0 {
+		t.Errorf("ports moved by %d, want a multiple of %d past the reserved block", shift, BlockStep)
+	}
+	for i, p := range ports {
+		if p.Field != base[i].Field || p.Port-base[i].Port != shift {
+			t.Errorf("%s moved from %d to %d, want every port moved by %d", p.Field, base[i].Port, p.Port, shift)
+		}
+		if reserved[p.Port] {
+			t.Errorf("%s moved to the reserved port %d", p.Field, p.Port)
+		}
+	}
+	if moved.WSRPCHTTPPort == cfg.WSRPCHTTPPort {
+		t.Error("the disabled WS port was not moved with the others")
+	}
+}
+
+func TestMoveToFreeBlockNone(t *testing.T) {
+	// every block pushes the P2P port past 65535
+	cfg := localConfig(freePort(t), freePort(t), freePort(t), 65535-BlockStep+1)
+	moved, err := MoveToFreeBlock(cfg, nil)
+	if !errors.Is(err, ErrNoFreeBlock) {
+		t.Fatalf("MoveToFreeBlock = %v, want ErrNoFreeBlock", err)
+	}
+	if !reflect.DeepEqual(moved, cfg) {
+		t.Errorf("MoveToFreeBlock changed the config it could not move")
+	}
+}
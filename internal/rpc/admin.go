@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// NodeInfo describes the local node (admin_nodeInfo).
+type NodeInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Enode string `json:"enode"`
+	ENR   string `json:"enr"`
+	IP    string `json:"ip"`
+	Ports struct {
+		Discovery int `json:"discovery"`
+		Listener  int `json:"listener"`
+	} `json:"ports"`
+	ListenAddr string                     `json:"listenAddr"`
+	Protocols  map[string]json.RawMessage `json:"protocols"`
+}
+
+// PeerInfo describes a connected peer (admin_peers).
+type PeerInfo struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Enode   string   `json:"enode"`
+	ENR     string   `json:"enr"`
+	Caps    []string `json:"caps"`
+	Network struct {
+		LocalAddress  string `json:"localAddress"`
+		RemoteAddress string `json:"remoteAddress"`
+		Inbound       bool   `json:"inbound"`
+		Trusted       bool   `json:"trusted"`
+		Static        bool   `json:"static"`
+	} `json:"network"`
+	Protocols map[string]json.RawMessage `json:"protocols"`
+}
+
+// NodeInfo returns information about the local node (admin_nodeInfo).
+func (c *Client) NodeInfo(ctx context.Context) (*NodeInfo, error) {
+	var info NodeInfo
+	if err := c.Call(ctx, &info, "admin_nodeInfo"); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Peers returns the connected peers (admin_peers).
+func (c *Client) Peers(ctx context.Context) ([]PeerInfo, error) {
+	var peers []PeerInfo
+	err := c.Call(ctx, &peers, "admin_peers")
+	return peers, err
+}
+
+// AddPeer asks the node to connect to enode and keep the connection (admin_addPeer).
+func (c *Client) AddPeer(ctx context.Context, enode string) (bool, error) {
+	return c.callBool(ctx, "admin_addPeer", enode)
+}
+
+// RemovePeer disconnects enode and stops reconnecting to it (admin_removePeer).
+func (c *Client) RemovePeer(ctx context.Context, enode string) (bool, error) {
+	return c.callBool(ctx, "admin_removePeer", enode)
+}
+
+// AddTrustedPeer lets enode connect even when the peer limit is reached (admin_addTrustedPeer).
+func (c *Client) AddTrustedPeer(ctx context.Context, enode string) (bool, error) {
+	return c.callBool(ctx, "admin_addTrustedPeer", enode)
+}
+
+// RemoveTrustedPeer removes enode from the trusted peers (admin_removeTrustedPeer).
+func (c *Client) RemoveTrustedPeer(ctx context.Context, enode string) (bool, error) {
+	return c.callBool(ctx, "admin_removeTrustedPeer", enode)
+}
+
+// DataDir returns the data directory of the node (admin_datadir).
+func (c *Client) DataDir(ctx context.Context) (string, error) {
+	var dir string
+	err := c.Call(ctx, &dir, "admin_datadir")
+	return dir, err
+}
+
+func (c *Client) callBool(ctx context.Context, method string, params ...interface{}) (bool, error) {
+	var ok bool
+	err := c.Call(ctx, &ok, method, params...)
+	return ok, err
+}
+
+// Direction returns "inbound" or "outbound".
+func (p PeerInfo) Direction() string {
+	if p.Network.Inbound {
+		return "inbound"
+	}
+	return "outbound"
+}
+
+// Head returns the head block hash the peer announced over the eth
+// protocol, empty when geth does not report it, as recent releases do not.
+func (p PeerInfo) Head() string {
+	var eth struct {
+		Head string `json:"head"`
+	}
+	if raw, ok := p.Protocols["eth"]; ok {
+		// geth reports "handshake" while the protocol is being negotiated
+		_ = json.Unmarshal(raw, &eth)
+	}
+	return eth.Head
+}
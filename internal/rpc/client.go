@@ -0,0 +1,195 @@
+// Package rpc is a JSON-RPC 2.0 client for the geth nodes launched by GeNe.
+// It talks to geth over HTTP, WebSocket or the IPC socket in its datadir.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds calls made with a context that has no deadline.
+const DefaultTimeout = 10 * time.Second
+
+var (
+	// ErrClosed is returned by calls made on a closed Client.
+	ErrClosed = errors.New("rpc client is closed")
+	// ErrNotificationsUnsupported is returned by Subscribe over HTTP.
+	ErrNotificationsUnsupported = errors.New("subscriptions need a WebSocket or IPC connection")
+	// ErrNoResult is returned when geth answers a call with neither a result nor an error.
+	ErrNoResult = errors.New("no result in JSON-RPC response")
+)
+
+// Error is an error returned by geth in a JSON-RPC response.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// BatchElem is one call of a batch. After BatchCall returns, Error is set
+// when that call failed, otherwise the result was stored into Result.
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	// Result is a pointer to the value the result is decoded into, nil to discard it
+	Result interface{}
+	Error  error
+}
+
+// message is a JSON-RPC request, response or notification.
+type message struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+func (m *message) decodeResult(result interface{}) error {
+	if m.Error != nil {
+		return m.Error
+	}
+	if len(m.Result) == 0 {
+		return ErrNoResult
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(m.Result, result)
+}
+
+// transport sends requests to geth and collects the responses, which may
+// come back in any order.
+type transport interface {
+	roundTrip(ctx context.Context, reqs []*message) ([]*message, error)
+	subscribe(ctx context.Context, c *Client, namespace string, args []interface{}) (*Subscription, error)
+	close() error
+}
+
+// Client is a connection to a geth node. It is safe for concurrent use.
+type Client struct {
+	// Timeout bounds calls made with a context that has no deadline (default: 10s)
+	Timeout time.Duration
+
+	t      transport
+	nextID uint64
+}
+
+// Dial connects to endpoint, which is an http:// or https:// URL, a ws://
+// or wss:// URL or the path of an IPC socket.
+func Dial(endpoint string) (*Client, error) {
+	switch {
+	case strings.HasPrefix(endpoint, "http://"), strings.HasPrefix(endpoint, "https://"):
+		return DialHTTP(endpoint)
+	case strings.HasPrefix(endpoint, "ws://"), strings.HasPrefix(endpoint, "wss://"):
+		return DialWebSocket(endpoint)
+	case endpoint == "":
+		return nil, errors.New("no endpoint to dial")
+	default:
+		return DialIPC(endpoint)
+	}
+}
+
+// Close closes the connection. Pending calls and subscriptions fail.
+func (c *Client) Close() error {
+	return c.t.close()
+}
+
+// Call invokes method with params and stores the result into result, a
+// pointer, unless it is nil.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	batch := []BatchElem{{Method: method, Params: params, Result: result}}
+	if err := c.BatchCall(ctx, batch); err != nil {
+		return err
+	}
+	return batch[0].Error
+}
+
+// BatchCall sends all elements of b in a single request. The error it
+// returns is about the request as a whole, the outcome of each call is
+// recorded in its BatchElem.
+func (c *Client) BatchCall(ctx context.Context, b []BatchElem) error {
+	if len(b) == 0 {
+		return nil
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqs := make([]*message, len(b))
+	byID := make(map[string]int, len(b))
+	for i, e := range b {
+		req, err := c.newRequest(e.Method, e.Params)
+		if err != nil {
+			return err
+		}
+		reqs[i] = req
+		byID[string(req.ID)] = i
+	}
+
+	resps, err := c.t.roundTrip(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	answered := make([]bool, len(b))
+	for _, resp := range resps {
+		i, ok := byID[string(resp.ID)]
+		if !ok {
+			continue
+		}
+		answered[i] = true
+		b[i].Error = resp.decodeResult(b[i].Result)
+	}
+	for i := range b {
+		if !answered[i] {
+			b[i].Error = ErrNoResult
+		}
+	}
+	return nil
+}
+
+// Subscribe starts a subscription in namespace, e.g. "eth" for
+// eth_subscribe, with args such as "newHeads". It needs a WebSocket or IPC
+// connection.
+func (c *Client) Subscribe(ctx context.Context, namespace string, args ...interface{}) (*Subscription, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.t.subscribe(ctx, c, namespace, args)
+}
+
+func (c *Client) newRequest(method string, params []interface{}) (*message, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s params: %w", method, err)
+	}
+	id := atomic.AddUint64(&c.nextID, 1)
+	return &message{
+		Version: "2.0",
+		ID:      json.RawMessage(fmt.Sprint(id)),
+		Method:  method,
+		Params:  raw,
+	}, nil
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,424 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// stub is a JSON-RPC server standing in for geth. It answers batches in
+// reverse order, as geth is free to, and sends notifications after the
+// response to a subscribe call.
+type stub struct {
+	// answer returns the result of req, or the error geth would send
+	answer func(req *message) (interface{}, *Error)
+	// unanswered are methods the stub never responds to
+	unanswered map[string]bool
+	// notifications are the results sent to every new subscription
+	notifications []string
+
+	mu   sync.Mutex
+	reqs []*message
+	subs int
+}
+
+// requests returns the requests received so far.
+func (s *stub) requests() []*message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*message(nil), s.reqs...)
+}
+
+// serve answers the single request or batch in data. It returns the
+// encoded response, nil when nothing is answered, and the ids of the
+// subscriptions it started.
+func (s *stub) serve(data []byte) ([]byte, []string) {
+	reqs, err := decodeMessages(data)
+	if err != nil {
+		panic(err)
+	}
+	var resps []*message
+	var subs []string
+	for i := len(reqs) - 1; i >= 0; i-- {
+		req := reqs[i]
+		s.mu.Lock()
+		s.reqs = append(s.reqs, req)
+		s.mu.Unlock()
+		if s.unanswered[req.Method] {
+			continue
+		}
+
+		resp := &message{Version: "2.0", ID: req.ID}
+		var result interface{}
+		switch {
+		case strings.HasSuffix(req.Method, "_subscribe"):
+			s.mu.Lock()
+			s.subs++
+			id := fmt.Sprintf("0x%x", s.subs)
+			s.mu.Unlock()
+			subs = append(subs, id)
+			result = id
+		case strings.HasSuffix(req.Method, "_unsubscribe"):
+			result = true
+		default:
+			result, resp.Error = s.answer(req)
+		}
+		if resp.Error == nil {
+			resp.Result, _ = json.Marshal(result)
+		}
+		resps = append(resps, resp)
+	}
+
+	switch {
+	case len(resps) == 0:
+		return nil, subs
+	case data[0] == '[':
+		out, _ := json.Marshal(resps)
+		return out, subs
+	default:
+		out, _ := json.Marshal(resps[0])
+		return out, subs
+	}
+}
+
+// serveStream answers the messages read from a persistent connection
+// until it is closed.
+func (s *stub) serveStream(read func() ([]byte, error), write func([]byte) error) {
+	for {
+		data, err := read()
+		if err != nil {
+			return
+		}
+		out, subs := s.serve(data)
+		if out != nil {
+			write(out)
+		}
+		for _, id := range subs {
+			for _, n := range s.notifications {
+				write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":%q,"result":%s}}`, id, n)))
+			}
+		}
+	}
+}
+
+// transports dial a client to a stub over each kind of connection.
+var transports = []struct {
+	name   string
+	stream bool
+	dial   func(t *testing.T, s *stub) *Client
+}{
+	{"http", false, dialHTTPStub},
+	{"ws", true, dialWebSocketStub},
+	{"ipc", true, dialIPCStub},
+}
+
+func dialHTTPStub(t *testing.T, s *stub) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		out, _ := s.serve(data)
+		if out == nil {
+			// geth has no way not to answer over HTTP, keep the call waiting
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func dialWebSocketStub(t *testing.T, s *stub) *Client {
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		s.serveStream(func() ([]byte, error) {
+			var data []byte
+			err := websocket.Message.Receive(ws, &data)
+			return data, err
+		}, func(data []byte) error {
+			return websocket.Message.Send(ws, string(data))
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := DialWebSocket("ws" + strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func dialIPCStub(t *testing.T, s *stub) *Client {
+	path := filepath.Join(t.TempDir(), "geth.ipc")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip("unix sockets are not available:", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			dec := json.NewDecoder(conn)
+			go s.serveStream(func() ([]byte, error) {
+				var raw json.RawMessage
+				err := dec.Decode(&raw)
+				return raw, err
+			}, func(data []byte) error {
+				_, err := conn.Write(data)
+				return err
+			})
+		}
+	}()
+
+	c, err := DialIPC(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// gethStub answers a few calls the way geth does.
+func gethStub() *stub {
+	return &stub{
+		answer: func(req *message) (interface{}, *Error) {
+			switch req.Method {
+			case "eth_blockNumber":
+				return "0x1b4", nil
+			case "web3_clientVersion":
+				return "Geth/v1.10.26-stable/linux-amd64/go1.18.5", nil
+			case "eth_mining", "miner_setGasPrice", "miner_setGasLimit", "miner_setEtherbase", "miner_setExtra":
+				return true, nil
+			case "miner_start", "miner_stop", "miner_setRecommitInterval":
+				return nil, nil
+			}
+			return nil, &Error{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		},
+		unanswered:    map[string]bool{"eth_syncing": true},
+		notifications: []string{`{"number":"0x1"}`, `{"number":"0x2"}`, `{"number":"0x3"}`},
+	}
+}
+
+func TestCall(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			c := tr.dial(t, gethStub())
+			ctx := context.Background()
+
+			n, err := c.BlockNumber(ctx)
+			if err != nil || n != 436 {
+				t.Errorf("BlockNumber = %d, %v, want 436", n, err)
+			}
+			v, err := c.ClientVersion(ctx)
+			if err != nil || !strings.HasPrefix(v, "Geth/v1.10.26") {
+				t.Errorf("ClientVersion = %q, %v", v, err)
+			}
+
+			err = c.Call(ctx, nil, "les_serverInfo")
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+				t.Errorf("Call to a missing method: error = %v, want code -32601", err)
+			}
+			// geth answers null to calls without a result
+			if err := c.Call(ctx, nil, "miner_stop"); err != nil {
+				t.Errorf("Call with a null result: %v", err)
+			}
+		})
+	}
+}
+
+func TestBatchCall(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			s := gethStub()
+			c := tr.dial(t, s)
+
+			var number Uint64
+			var version string
+			var mining bool
+			batch := []BatchElem{
+				{Method: "eth_blockNumber", Result: &number},
+				{Method: "les_serverInfo", Result: new(json.RawMessage)},
+				{Method: "web3_clientVersion", Result: &version},
+				{Method: "eth_syncing", Result: new(json.RawMessage)},
+				{Method: "eth_mining", Result: &mining},
+				{Method: "eth_blockNumber"},
+			}
+			if err := c.BatchCall(context.Background(), batch); err != nil {
+				t.Fatal(err)
+			}
+
+			if batch[0].Error != nil || number != 436 {
+				t.Errorf("eth_blockNumber = %d, %v", number, batch[0].Error)
+			}
+			var rpcErr *Error
+			if !errors.As(batch[1].Error, &rpcErr) || rpcErr.Code != -32601 {
+				t.Errorf("les_serverInfo error = %v, want code -32601", batch[1].Error)
+			}
+			if batch[2].Error != nil || !strings.HasPrefix(version, "Geth/") {
+				t.Errorf("web3_clientVersion = %q, %v", version, batch[2].Error)
+			}
+			if !errors.Is(batch[3].Error, ErrNoResult) {
+				t.Errorf("unanswered eth_syncing error = %v, want ErrNoResult", batch[3].Error)
+			}
+			if batch[4].Error != nil || !mining {
+				t.Errorf("eth_mining = %v, %v", mining, batch[4].Error)
+			}
+			if batch[5].Error != nil {
+				t.Errorf("eth_blockNumber without a result: %v", batch[5].Error)
+			}
+
+			// the whole batch went out in one request with distinct ids
+			reqs := s.requests()
+			ids := make(map[string]bool)
+			for _, req := range reqs {
+				ids[string(req.ID)] = true
+			}
+			if len(reqs) != len(batch) || len(ids) != len(batch) {
+				t.Errorf("stub received %d requests with %d ids, want %d", len(reqs), len(ids), len(batch))
+			}
+
+			if err := c.BatchCall(context.Background(), nil); err != nil {
+				t.Errorf("empty BatchCall: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			s := gethStub()
+			c := tr.dial(t, s)
+			ctx := context.Background()
+
+			sub, err := c.SubscribeNewHeads(ctx)
+			if !tr.stream {
+				if !errors.Is(err, ErrNotificationsUnsupported) {
+					t.Errorf("Subscribe over HTTP: error = %v, want ErrNotificationsUnsupported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sub.ID() != "0x1" {
+				t.Errorf("ID = %q, want 0x1", sub.ID())
+			}
+
+			for _, want := range s.notifications {
+				select {
+				case got := <-sub.Notifications():
+					if string(got) != want {
+						t.Errorf("notification = %s, want %s", got, want)
+					}
+				case err := <-sub.Err():
+					t.Fatalf("subscription ended: %v", err)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("no notification %s", want)
+				}
+			}
+
+			if err := sub.Unsubscribe(); err != nil {
+				t.Errorf("Unsubscribe: %v", err)
+			}
+			if err, ok := <-sub.Err(); ok {
+				t.Errorf("Err after Unsubscribe = %v, want a closed channel", err)
+			}
+			reqs := s.requests()
+			last := reqs[len(reqs)-1]
+			if last.Method != "eth_unsubscribe" || string(last.Params) != `["0x1"]` {
+				t.Errorf("last request = %s %s, want eth_unsubscribe [\"0x1\"]", last.Method, last.Params)
+			}
+
+			// closing the client ends the other subscriptions
+			sub, err = c.SubscribeNewHeads(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Close()
+			select {
+			case err := <-sub.Err():
+				if !errors.Is(err, ErrClosed) {
+					t.Errorf("Err after Close = %v, want ErrClosed", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("subscription did not end on Close")
+			}
+			if _, err := c.BlockNumber(ctx); !errors.Is(err, ErrClosed) {
+				t.Errorf("BlockNumber after Close: error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			c := tr.dial(t, gethStub())
+			c.Timeout = 50 * time.Millisecond
+
+			start := time.Now()
+			_, err := c.Syncing(context.Background())
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("unanswered call: error = %v, want context.DeadlineExceeded", err)
+			}
+			if d := time.Since(start); d > DefaultTimeout/2 {
+				t.Errorf("unanswered call took %s, want about %s", d, c.Timeout)
+			}
+
+			// the client still works after a call timed out
+			if n, err := c.BlockNumber(context.Background()); err != nil || n != 436 {
+				t.Errorf("BlockNumber after a timeout = %d, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	c := &Client{}
+	ctx, cancel := c.withTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if left := time.Until(deadline); !ok || left > DefaultTimeout || left < DefaultTimeout-time.Second {
+		t.Errorf("deadline in %s, want %s", left, DefaultTimeout)
+	}
+
+	// a deadline set by the caller is kept, even a later one
+	later, cancelLater := context.WithTimeout(context.Background(), 2*DefaultTimeout)
+	defer cancelLater()
+	ctx, cancel = c.withTimeout(later)
+	defer cancel()
+	if d, _ := ctx.Deadline(); !d.Equal(mustDeadline(later)) {
+		t.Errorf("deadline = %s, want the caller's %s", d, mustDeadline(later))
+	}
+}
+
+func mustDeadline(ctx context.Context) time.Time {
+	d, _ := ctx.Deadline()
+	return d
+}
@@ -0,0 +1,21 @@
+package rpc
+
+import "context"
+
+// DebugSetVerbosity sets the log verbosity, 0 (silent) to 5 (detail) (debug_verbosity).
+func (c *Client) DebugSetVerbosity(ctx context.Context, level int) error {
+	return c.Call(ctx, nil, "debug_verbosity", level)
+}
+
+// DebugVmodule sets the per-package log verbosity pattern, e.g.
+// "eth/*=5,p2p=4" (debug_vmodule).
+func (c *Client) DebugVmodule(ctx context.Context, pattern string) error {
+	return c.Call(ctx, nil, "debug_vmodule", pattern)
+}
+
+// DebugStacks returns the stack traces of all goroutines (debug_stacks).
+func (c *Client) DebugStacks(ctx context.Context) (string, error) {
+	var stacks string
+	err := c.Call(ctx, &stacks, "debug_stacks")
+	return stacks, err
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+)
+
+// SyncProgress is the result of eth_syncing while geth is syncing. The
+// state and snap sync counters are zero on releases that do not report them.
+type SyncProgress struct {
+	StartingBlock Uint64 `json:"startingBlock"`
+	CurrentBlock  Uint64 `json:"currentBlock"`
+	HighestBlock  Uint64 `json:"highestBlock"`
+
+	PulledStates Uint64 `json:"pulledStates"`
+	KnownStates  Uint64 `json:"knownStates"`
+
+	SyncedAccounts   Uint64 `json:"syncedAccounts"`
+	SyncedStorage    Uint64 `json:"syncedStorage"`
+	SyncedBytecodes  Uint64 `json:"syncedBytecodes"`
+	HealedTrienodes  Uint64 `json:"healedTrienodes"`
+	HealingTrienodes Uint64 `json:"healingTrienodes"`
+}
+
+// Header is the part of a block header GeNe shows.
+type Header struct {
+	Number     Uint64 `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Time       Uint64 `json:"timestamp"`
+	Miner      string `json:"miner"`
+	GasUsed    Uint64 `json:"gasUsed"`
+	GasLimit   Uint64 `json:"gasLimit"`
+}
+
+// ClientVersion returns the version string of the node (web3_clientVersion).
+func (c *Client) ClientVersion(ctx context.Context) (string, error) {
+	var v string
+	err := c.Call(ctx, &v, "web3_clientVersion")
+	return v, err
+}
+
+// BlockNumber returns the number of the most recent block (eth_blockNumber).
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var n Uint64
+	err := c.Call(ctx, &n, "eth_blockNumber")
+	return uint64(n), err
+}
+
+// ChainID returns the chain id used to sign transactions (eth_chainId).
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.callBig(ctx, "eth_chainId")
+}
+
+// GasPrice returns the suggested gas price in wei (eth_gasPrice).
+func (c *Client) GasPrice(ctx context.Context) (*big.Int, error) {
+	return c.callBig(ctx, "eth_gasPrice")
+}
+
+// Balance returns the balance in wei of address at block, "latest" when
+// block is empty (eth_getBalance).
+func (c *Client) Balance(ctx context.Context, address, block string) (*big.Int, error) {
+	if block == "" {
+		block = "latest"
+	}
+	return c.callBig(ctx, "eth_getBalance", address, block)
+}
+
+// Accounts returns the addresses owned by the node (eth_accounts).
+func (c *Client) Accounts(ctx context.Context) ([]string, error) {
+	var accounts []string
+	err := c.Call(ctx, &accounts, "eth_accounts")
+	return accounts, err
+}
+
+// Coinbase returns the address mining rewards go to (eth_coinbase).
+func (c *Client) Coinbase(ctx context.Context) (string, error) {
+	var addr string
+	err := c.Call(ctx, &addr, "eth_coinbase")
+	return addr, err
+}
+
+// Mining reports whether the node is mining (eth_mining).
+func (c *Client) Mining(ctx context.Context) (bool, error) {
+	var mining bool
+	err := c.Call(ctx, &mining, "eth_mining")
+	return mining, err
+}
+
+// Hashrate returns the hashes per second the node mines at (eth_hashrate).
+func (c *Client) Hashrate(ctx context.Context) (uint64, error) {
+	var h Uint64
+	err := c.Call(ctx, &h, "eth_hashrate")
+	return uint64(h), err
+}
+
+// Syncing returns the sync progress, or nil when geth is not syncing (eth_syncing).
+func (c *Client) Syncing(ctx context.Context) (*SyncProgress, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, &raw, "eth_syncing"); err != nil {
+		return nil, err
+	}
+	return DecodeSyncing(raw)
+}
+
+// DecodeSyncing decodes an eth_syncing result, e.g. from a batch: false
+// when geth is not syncing, which gives nil, or its sync progress.
+func DecodeSyncing(raw json.RawMessage) (*SyncProgress, error) {
+	var syncing bool
+	if err := json.Unmarshal(raw, &syncing); err == nil {
+		return nil, nil
+	}
+	var p SyncProgress
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// HeaderByNumber returns the header of block number, which is a hex
+// quantity or a tag such as "latest", or nil if there is no such block
+// (eth_getBlockByNumber).
+func (c *Client) HeaderByNumber(ctx context.Context, number string) (*Header, error) {
+	var h *Header
+	err := c.Call(ctx, &h, "eth_getBlockByNumber", number, false)
+	return h, err
+}
+
+// SubscribeNewHeads subscribes to new chain heads. Every notification
+// decodes into a Header.
+func (c *Client) SubscribeNewHeads(ctx context.Context) (*Subscription, error) {
+	return c.Subscribe(ctx, "eth", "newHeads")
+}
+
+// NetVersion returns the network id (net_version).
+func (c *Client) NetVersion(ctx context.Context) (string, error) {
+	var v string
+	err := c.Call(ctx, &v, "net_version")
+	return v, err
+}
+
+// PeerCount returns the number of connected peers (net_peerCount).
+func (c *Client) PeerCount(ctx context.Context) (uint64, error) {
+	var n Uint64
+	err := c.Call(ctx, &n, "net_peerCount")
+	return uint64(n), err
+}
+
+// Listening reports whether the node accepts peer connections (net_listening).
+func (c *Client) Listening(ctx context.Context) (bool, error) {
+	var listening bool
+	err := c.Call(ctx, &listening, "net_listening")
+	return listening, err
+}
+
+func (c *Client) callBig(ctx context.Context, method string, params ...interface{}) (*big.Int, error) {
+	var v Big
+	if err := c.Call(ctx, &v, method, params...); err != nil {
+		return nil, err
+	}
+	return v.Int(), nil
+}
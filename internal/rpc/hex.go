@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Uint64 is a quantity geth encodes as a 0x-prefixed hex string.
+type Uint64 uint64
+
+// UnmarshalJSON decodes a hex quantity such as "0x1b4".
+func (u *Uint64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := parseHex(s)
+	if err != nil {
+		return err
+	}
+	if !v.IsUint64() {
+		return fmt.Errorf("hex quantity %s does not fit in 64 bits", s)
+	}
+	*u = Uint64(v.Uint64())
+	return nil
+}
+
+// MarshalJSON encodes u as a hex quantity.
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + strconv.FormatUint(uint64(u), 16))
+}
+
+// Big is a big integer quantity geth encodes as a 0x-prefixed hex string.
+type Big big.Int
+
+// UnmarshalJSON decodes a hex quantity such as "0x3b9aca00".
+func (b *Big) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := parseHex(s)
+	if err != nil {
+		return err
+	}
+	*b = Big(*v)
+	return nil
+}
+
+// MarshalJSON encodes b as a hex quantity.
+func (b *Big) MarshalJSON() ([]byte, error) {
+	return json.Marshal(EncodeBig(b.Int()))
+}
+
+// Int returns b as a *big.Int.
+func (b *Big) Int() *big.Int {
+	return (*big.Int)(b)
+}
+
+// EncodeBig encodes v as a hex quantity, e.g. for call parameters.
+func EncodeBig(v *big.Int) string {
+	if v.Sign() < 0 {
+		return "-0x" + new(big.Int).Neg(v).Text(16)
+	}
+	return "0x" + v.Text(16)
+}
+
+func parseHex(s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if digits == s || digits == "" {
+		return nil, fmt.Errorf("%q is not a hex quantity", s)
+	}
+	v, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a hex quantity", s)
+	}
+	return v, nil
+}
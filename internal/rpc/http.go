@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseSize caps the body read from an HTTP response.
+const maxResponseSize = 32 << 20
+
+// DialHTTP returns a client for the HTTP-RPC server at url. No connection
+// is made until the first call.
+func DialHTTP(url string) (*Client, error) {
+	return &Client{t: &httpTransport{url: url, client: &http.Client{}}}, nil
+}
+
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (t *httpTransport) roundTrip(ctx context.Context, reqs []*message) ([]*message, error) {
+	var body []byte
+	var err error
+	if len(reqs) == 1 {
+		body, err = json.Marshal(reqs[0])
+	} else {
+		body, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s: %s", t.url, resp.Status, bytes.TrimSpace(data))
+	}
+	return decodeMessages(data)
+}
+
+func (t *httpTransport) subscribe(context.Context, *Client, string, []interface{}) (*Subscription, error) {
+	return nil, ErrNotificationsUnsupported
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// decodeMessages decodes a single JSON-RPC message or a batch of them.
+func decodeMessages(data []byte) ([]*message, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var msgs []*message
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, fmt.Errorf("decoding JSON-RPC batch: %w", err)
+		}
+		return msgs, nil
+	}
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decoding JSON-RPC response: %w", err)
+	}
+	return []*message{&msg}, nil
+}
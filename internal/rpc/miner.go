@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// MinerStart starts mining with threads CPU threads, or geth's current
+// setting when threads is zero (miner_start).
+func (c *Client) MinerStart(ctx context.Context, threads int) error {
+	if threads > 0 {
+		return c.Call(ctx, nil, "miner_start", threads)
+	}
+	return c.Call(ctx, nil, "miner_start")
+}
+
+// MinerStop stops mining (miner_stop).
+func (c *Client) MinerStop(ctx context.Context) error {
+	return c.Call(ctx, nil, "miner_stop")
+}
+
+// MinerSetEtherbase sets the address mining rewards go to (miner_setEtherbase).
+func (c *Client) MinerSetEtherbase(ctx context.Context, address string) (bool, error) {
+	return c.callBool(ctx, "miner_setEtherbase", address)
+}
+
+// MinerSetGasPrice sets the minimum gas price, in wei, of mined transactions (miner_setGasPrice).
+func (c *Client) MinerSetGasPrice(ctx context.Context, price *big.Int) (bool, error) {
+	return c.callBool(ctx, "miner_setGasPrice", EncodeBig(price))
+}
+
+// MinerSetExtra sets the extra data of mined blocks (miner_setExtra).
+func (c *Client) MinerSetExtra(ctx context.Context, extra string) (bool, error) {
+	return c.callBool(ctx, "miner_setExtra", extra)
+}
+
+// MinerSetGasLimit sets the gas ceiling of mined blocks (miner_setGasLimit).
+func (c *Client) MinerSetGasLimit(ctx context.Context, limit uint64) (bool, error) {
+	return c.callBool(ctx, "miner_setGasLimit", Uint64(limit))
+}
+
+// MinerSetRecommitInterval sets how often the block being mined is
+// recreated (miner_setRecommitInterval).
+func (c *Client) MinerSetRecommitInterval(ctx context.Context, interval time.Duration) error {
+	return c.Call(ctx, nil, "miner_setRecommitInterval", interval.Milliseconds())
+}
@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestMinerParams(t *testing.T) {
+	price, _ := new(big.Int).SetString("1000000000", 10)
+	huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+	tests := []struct {
+		name   string
+		call   func(c *Client) error
+		method string
+		params string
+	}{
+		{"start", func(c *Client) error { return c.MinerStart(context.Background(), 0) }, "miner_start", `[]`},
+		{"start threads", func(c *Client) error { return c.MinerStart(context.Background(), 4) }, "miner_start", `[4]`},
+		{"stop", func(c *Client) error { return c.MinerStop(context.Background()) }, "miner_stop", `[]`},
+		{"etherbase", func(c *Client) error {
+			_, err := c.MinerSetEtherbase(context.Background(), "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+			return err
+		}, "miner_setEtherbase", `["0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"]`},
+		{"gas price", func(c *Client) error {
+			_, err := c.MinerSetGasPrice(context.Background(), price)
+			return err
+		}, "miner_setGasPrice", `["0x3b9aca00"]`},
+		{"zero gas price", func(c *Client) error {
+			_, err := c.MinerSetGasPrice(context.Background(), new(big.Int))
+			return err
+		}, "miner_setGasPrice", `["0x0"]`},
+		{"gas price above 64 bits", func(c *Client) error {
+			_, err := c.MinerSetGasPrice(context.Background(), huge)
+			return err
+		}, "miner_setGasPrice", `["0x56bc75e2d63100000"]`},
+		{"gas limit", func(c *Client) error {
+			_, err := c.MinerSetGasLimit(context.Background(), 30000000)
+			return err
+		}, "miner_setGasLimit", `["0x1c9c380"]`},
+		{"extra", func(c *Client) error {
+			_, err := c.MinerSetExtra(context.Background(), "GeNe")
+			return err
+		}, "miner_setExtra", `["GeNe"]`},
+		{"recommit", func(c *Client) error {
+			return c.MinerSetRecommitInterval(context.Background(), 2500*time.Millisecond)
+		}, "miner_setRecommitInterval", `[2500]`},
+	}
+	for _, tt := range tests {
+		s := gethStub()
+		c := dialHTTPStub(t, s)
+		if err := tt.call(c); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		reqs := s.requests()
+		if len(reqs) != 1 || reqs[0].Method != tt.method || string(reqs[0].Params) != tt.params {
+			t.Errorf("%s: sent %v, want %s %s", tt.name, reqs, tt.method, tt.params)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	var u Uint64
+	for in, want := range map[string]Uint64{`"0x0"`: 0, `"0x1b4"`: 436, `"0XFF"`: 255, `"0xffffffffffffffff"`: 1<<64 - 1} {
+		if err := json.Unmarshal([]byte(in), &u); err != nil || u != want {
+			t.Errorf("Uint64 %s = %d, %v, want %d", in, u, err, want)
+		}
+	}
+	for _, in := range []string{`"0x"`, `"1b4"`, `"0xzz"`, `436`, `"0x10000000000000000"`} {
+		if err := json.Unmarshal([]byte(in), &u); err == nil {
+			t.Errorf("Uint64 accepted %s", in)
+		}
+	}
+	if data, _ := json.Marshal(Uint64(30000000)); string(data) != `"0x1c9c380"` {
+		t.Errorf("Uint64 encodes as %s", data)
+	}
+
+	var b Big
+	if err := json.Unmarshal([]byte(`"0x56bc75e2d63100000"`), &b); err != nil || b.Int().String() != "100000000000000000000" {
+		t.Errorf("Big = %s, %v", b.Int(), err)
+	}
+	if data, _ := json.Marshal(&b); string(data) != `"0x56bc75e2d63100000"` {
+		t.Errorf("Big encodes as %s", data)
+	}
+	if s := EncodeBig(big.NewInt(-255)); s != "-0xff" {
+		t.Errorf("EncodeBig(-255) = %s", s)
+	}
+}
@@ -0,0 +1,284 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// wsOrigin is the Origin sent in the WebSocket handshake. geth accepts it
+// when --ws.origins is not set.
+const wsOrigin = "http://localhost"
+
+// DialIPC connects to the IPC socket at path.
+func DialIPC(path string) (*Client, error) {
+	c, err := net.DialTimeout("unix", path, DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{t: newStream(&ipcConn{conn: c, dec: json.NewDecoder(c)})}, nil
+}
+
+// DialWebSocket connects to the WebSocket-RPC server at url.
+func DialWebSocket(url string) (*Client, error) {
+	cfg, err := websocket.NewConfig(url, wsOrigin)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dialer = &net.Dialer{Timeout: DefaultTimeout}
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{t: newStream(&wsConn{ws: ws})}, nil
+}
+
+// conn carries whole JSON-RPC messages over a persistent connection.
+type conn interface {
+	read() ([]byte, error)
+	write(data []byte) error
+	close() error
+}
+
+type ipcConn struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+func (c *ipcConn) read() ([]byte, error) {
+	var raw json.RawMessage
+	err := c.dec.Decode(&raw)
+	return raw, err
+}
+
+func (c *ipcConn) write(data []byte) error {
+	_, err := c.conn.Write(data)
+	return err
+}
+
+func (c *ipcConn) close() error { return c.conn.Close() }
+
+type wsConn struct {
+	ws *websocket.Conn
+}
+
+func (c *wsConn) read() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.ws, &data)
+	return data, err
+}
+
+func (c *wsConn) write(data []byte) error {
+	return websocket.Message.Send(c.ws, string(data))
+}
+
+func (c *wsConn) close() error { return c.ws.Close() }
+
+// call waits for the responses to the requests sent in one round trip.
+// They arrive together, as geth answers a batch with a single message.
+type call struct {
+	resps chan []*message
+	// sub is registered when the response to its subscribe request arrives,
+	// so no notification sent right after it is missed
+	sub *Subscription
+}
+
+// streamTransport multiplexes calls and subscriptions over one connection.
+type streamTransport struct {
+	conn    conn
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]*call
+	subs    map[string]*Subscription
+	err     error
+	failed  chan struct{}
+}
+
+func newStream(c conn) *streamTransport {
+	t := &streamTransport{
+		conn:    c,
+		pending: make(map[string]*call),
+		subs:    make(map[string]*Subscription),
+		failed:  make(chan struct{}),
+	}
+	go t.readLoop()
+	return t
+}
+
+func (t *streamTransport) roundTrip(ctx context.Context, reqs []*message) ([]*message, error) {
+	return t.send(ctx, reqs, nil)
+}
+
+func (t *streamTransport) send(ctx context.Context, reqs []*message, sub *Subscription) ([]*message, error) {
+	var data []byte
+	var err error
+	if len(reqs) == 1 {
+		data, err = json.Marshal(reqs[0])
+	} else {
+		data, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &call{resps: make(chan []*message, 1), sub: sub}
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return nil, t.err
+	}
+	for _, req := range reqs {
+		t.pending[string(req.ID)] = c
+	}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		for _, req := range reqs {
+			delete(t.pending, string(req.ID))
+		}
+		t.mu.Unlock()
+	}()
+
+	t.writeMu.Lock()
+	err = t.conn.write(data)
+	t.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resps := <-c.resps:
+		return resps, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.failed:
+		return nil, t.err
+	}
+}
+
+func (t *streamTransport) subscribe(ctx context.Context, client *Client, namespace string, args []interface{}) (*Subscription, error) {
+	req, err := client.newRequest(namespace+"_subscribe", args)
+	if err != nil {
+		return nil, err
+	}
+	sub := newSubscription(t, client, namespace)
+	resps, err := t.send(ctx, []*message{req}, sub)
+	if err != nil {
+		return nil, err
+	}
+	if err := resps[0].decodeResult(nil); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// readLoop passes responses to the waiting calls and notifications to
+// their subscriptions until the connection fails.
+func (t *streamTransport) readLoop() {
+	for {
+		data, err := t.conn.read()
+		if err != nil {
+			t.fail(err)
+			return
+		}
+		msgs, err := decodeMessages(data)
+		if err != nil {
+			continue
+		}
+		var resps []*message
+		for _, msg := range msgs {
+			if strings.HasSuffix(msg.Method, "_subscription") {
+				t.notify(msg)
+			} else if msg.ID != nil {
+				resps = append(resps, msg)
+			}
+		}
+		if len(resps) > 0 {
+			t.respond(resps)
+		}
+	}
+}
+
+// respond passes the responses read in one message to the calls waiting
+// for them. geth answers a batch in one message, so the responses missing
+// from it are not waited for.
+func (t *streamTransport) respond(resps []*message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byCall := make(map[*call][]*message)
+	var calls []*call
+	for _, msg := range resps {
+		c, ok := t.pending[string(msg.ID)]
+		if !ok {
+			// the call gave up already
+			continue
+		}
+		if c.sub != nil && msg.Error == nil {
+			if err := json.Unmarshal(msg.Result, &c.sub.id); err == nil {
+				t.subs[c.sub.id] = c.sub
+			}
+		}
+		if _, ok := byCall[c]; !ok {
+			calls = append(calls, c)
+		}
+		byCall[c] = append(byCall[c], msg)
+	}
+	for _, c := range calls {
+		select {
+		case c.resps <- byCall[c]:
+		default:
+			// the call was answered already
+		}
+	}
+}
+
+func (t *streamTransport) notify(msg *message) {
+	var params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+	t.mu.Lock()
+	sub, ok := t.subs[params.Subscription]
+	t.mu.Unlock()
+	if ok {
+		sub.deliver(params.Result)
+	}
+}
+
+// fail ends every pending call and subscription with err.
+func (t *streamTransport) fail(err error) {
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return
+	}
+	t.err = err
+	close(t.failed)
+	subs := t.subs
+	t.subs = make(map[string]*Subscription)
+	t.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.end(err)
+	}
+}
+
+func (t *streamTransport) forget(sub *Subscription) {
+	t.mu.Lock()
+	delete(t.subs, sub.id)
+	t.mu.Unlock()
+}
+
+func (t *streamTransport) close() error {
+	t.fail(ErrClosed)
+	return t.conn.close()
+}
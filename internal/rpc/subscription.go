@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// subscriptionBuffer is the number of notifications queued for a slow reader.
+const subscriptionBuffer = 256
+
+// ErrSubscriptionOverflow ends a subscription whose reader fell too far behind.
+var ErrSubscriptionOverflow = errors.New("subscription notifications were not read fast enough")
+
+// Subscription receives the notifications of a namespace_subscribe call.
+type Subscription struct {
+	t         *streamTransport
+	client    *Client
+	namespace string
+	id        string
+
+	ch   chan json.RawMessage
+	errc chan error
+	once sync.Once
+}
+
+func newSubscription(t *streamTransport, client *Client, namespace string) *Subscription {
+	return &Subscription{
+		t:         t,
+		client:    client,
+		namespace: namespace,
+		ch:        make(chan json.RawMessage, subscriptionBuffer),
+		errc:      make(chan error, 1),
+	}
+}
+
+// ID returns the subscription id assigned by geth.
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// Notifications returns the channel the notification results are sent to.
+func (s *Subscription) Notifications() <-chan json.RawMessage {
+	return s.ch
+}
+
+// Err returns a channel that receives the error ending the subscription,
+// e.g. when the connection is lost. It is closed by Unsubscribe.
+func (s *Subscription) Err() <-chan error {
+	return s.errc
+}
+
+// Unsubscribe ends the subscription and tells geth to stop sending notifications.
+func (s *Subscription) Unsubscribe() error {
+	s.t.forget(s)
+	s.end(nil)
+	var ok bool
+	err := s.client.Call(context.Background(), &ok, s.namespace+"_unsubscribe", s.id)
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Subscription) deliver(result json.RawMessage) {
+	select {
+	case s.ch <- result:
+	default:
+		s.t.forget(s)
+		s.end(ErrSubscriptionOverflow)
+	}
+}
+
+// end reports err, if any, on the Err channel and closes it.
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		if err != nil {
+			s.errc <- err
+		}
+		close(s.errc)
+	})
+}
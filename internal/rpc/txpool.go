@@ -0,0 +1,26 @@
+package rpc
+
+import "context"
+
+// TxPoolStatus counts the transactions in the pool (txpool_status).
+type TxPoolStatus struct {
+	// Pending transactions can be included in the next block
+	Pending Uint64 `json:"pending"`
+	// Queued transactions wait for an earlier nonce
+	Queued Uint64 `json:"queued"`
+}
+
+// TxPoolStatus returns the number of pending and queued transactions (txpool_status).
+func (c *Client) TxPoolStatus(ctx context.Context) (TxPoolStatus, error) {
+	var s TxPoolStatus
+	err := c.Call(ctx, &s, "txpool_status")
+	return s, err
+}
+
+// TxPoolInspect summarises the pooled transactions per sender and nonce,
+// keyed by "pending" and "queued" (txpool_inspect).
+func (c *Client) TxPoolInspect(ctx context.Context) (map[string]map[string]map[string]string, error) {
+	var content map[string]map[string]map[string]string
+	err := c.Call(ctx, &content, "txpool_inspect")
+	return content, err
+}
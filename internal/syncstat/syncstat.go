@@ -0,0 +1,151 @@
+// Package syncstat follows the sync progress of a running geth node.
+package syncstat
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gene/internal/rpc"
+)
+
+// DefaultWindow is the period sync rates are averaged over.
+const DefaultWindow = 2 * time.Minute
+
+// Sample is the sync state of a node at one point in time.
+type Sample struct {
+	Time time.Time
+	// Block is the number of the head block (eth_blockNumber)
+	Block uint64
+	// Peers is the number of connected peers (net_peerCount)
+	Peers uint64
+	// Progress is the sync progress, nil when geth is not syncing (eth_syncing)
+	Progress *rpc.SyncProgress
+}
+
+// Syncing reports whether geth was syncing.
+func (s Sample) Syncing() bool {
+	return s.Progress != nil
+}
+
+// Current returns the block geth had synced up to.
+func (s Sample) Current() uint64 {
+	if s.Progress != nil {
+		return uint64(s.Progress.CurrentBlock)
+	}
+	return s.Block
+}
+
+// Highest returns the highest block announced by the peers, the head
+// block when geth is not syncing.
+func (s Sample) Highest() uint64 {
+	if s.Progress != nil && uint64(s.Progress.HighestBlock) > s.Current() {
+		return uint64(s.Progress.HighestBlock)
+	}
+	return s.Current()
+}
+
+// Fraction returns how much of the chain has been synced, from 0 to 1.
+func (s Sample) Fraction() float64 {
+	if s.Highest() == 0 {
+		if s.Syncing() {
+			return 0
+		}
+		return 1
+	}
+	return float64(s.Current()) / float64(s.Highest())
+}
+
+// Fetch reads a Sample from client in a single batch request.
+func Fetch(ctx context.Context, client *rpc.Client) (Sample, error) {
+	var (
+		syncing json.RawMessage
+		block   rpc.Uint64
+		peers   rpc.Uint64
+	)
+	batch := []rpc.BatchElem{
+		{Method: "eth_syncing", Result: &syncing},
+		{Method: "eth_blockNumber", Result: &block},
+		{Method: "net_peerCount", Result: &peers},
+	}
+	s := Sample{Time: time.Now()}
+	if err := client.BatchCall(ctx, batch); err != nil {
+		return s, err
+	}
+	for _, e := range batch {
+		if e.Error != nil {
+			return s, e.Error
+		}
+	}
+	progress, err := rpc.DecodeSyncing(syncing)
+	if err != nil {
+		return s, err
+	}
+	s.Block, s.Peers, s.Progress = uint64(block), uint64(peers), progress
+	return s, nil
+}
+
+// Tracker keeps the recent samples of a node to derive rates from them.
+type Tracker struct {
+	// Window is the period rates are averaged over (default: 2m)
+	Window time.Duration
+
+	samples []Sample
+}
+
+// Add records s, which must be newer than the samples added before.
+func (t *Tracker) Add(s Sample) {
+	t.samples = append(t.samples, s)
+	window := t.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	// keep the newest sample older than the window, so rates span all of it
+	cutoff := s.Time.Add(-window)
+	for len(t.samples) > 2 && !t.samples[1].Time.After(cutoff) {
+		t.samples = t.samples[1:]
+	}
+}
+
+// Reset forgets every sample, e.g. when another node is followed.
+func (t *Tracker) Reset() {
+	t.samples = nil
+}
+
+// Last returns the most recent sample.
+func (t *Tracker) Last() (Sample, bool) {
+	if len(t.samples) == 0 {
+		return Sample{}, false
+	}
+	return t.samples[len(t.samples)-1], true
+}
+
+// BlocksPerSecond returns the rate at which blocks were synced over the window.
+func (t *Tracker) BlocksPerSecond() float64 {
+	if len(t.samples) < 2 {
+		return 0
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	elapsed := last.Time.Sub(first.Time).Seconds()
+	if elapsed <= 0 || last.Current() < first.Current() {
+		return 0
+	}
+	return float64(last.Current()-first.Current()) / elapsed
+}
+
+// ETA estimates how long the block sync needs to reach the highest known
+// block at the current rate. It reports false while no estimate can be
+// made, e.g. when no blocks were imported during the window, as happens
+// while a snap sync downloads state.
+func (t *Tracker) ETA() (time.Duration, bool) {
+	last, ok := t.Last()
+	if !ok || !last.Syncing() {
+		return 0, false
+	}
+	rate := t.BlocksPerSecond()
+	if rate <= 0 {
+		return 0, false
+	}
+	remaining := float64(last.Highest() - last.Current())
+	return time.Duration(remaining / rate * float64(time.Second)), true
+}
@@ -0,0 +1,147 @@
+package syncstat
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gene/internal/rpc"
+)
+
+var t0 = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// at returns a sample taken sec seconds after t0.
+func at(sec int, current, highest uint64) Sample {
+	return Sample{
+		Time:     t0.Add(time.Duration(sec) * time.Second),
+		Progress: &rpc.SyncProgress{CurrentBlock: rpc.Uint64(current), HighestBlock: rpc.Uint64(highest)},
+	}
+}
+
+// synced returns a sample of a node that is not syncing, with head block head.
+func synced(sec int, head uint64) Sample {
+	return Sample{Time: t0.Add(time.Duration(sec) * time.Second), Block: head}
+}
+
+func TestSampleFraction(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample Sample
+		want   float64
+	}{
+		{"halfway", at(0, 500, 1000), 0.5},
+		{"start", at(0, 0, 1000), 0},
+		{"nothing known yet", at(0, 0, 0), 0},
+		{"current past highest", at(0, 1200, 1000), 1},
+		{"synced", synced(0, 1000), 1},
+		{"synced genesis", synced(0, 0), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.sample.Fraction(); got != tt.want {
+			t.Errorf("%s: Fraction = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrackerAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  time.Duration
+		add     []Sample
+		wantSec []int
+	}{
+		{"single", time.Minute, []Sample{at(0, 0, 100)}, []int{0}},
+		{"two old samples are kept", time.Minute, []Sample{at(0, 0, 100), at(600, 10, 100)}, []int{0, 600}},
+		{
+			"within the window",
+			time.Minute,
+			[]Sample{at(0, 0, 100), at(20, 1, 100), at(40, 2, 100), at(60, 3, 100)},
+			[]int{0, 20, 40, 60},
+		},
+		{
+			"newest sample older than the window is kept",
+			time.Minute,
+			[]Sample{at(0, 0, 100), at(20, 1, 100), at(40, 2, 100), at(70, 3, 100), at(90, 4, 100)},
+			[]int{20, 40, 70, 90},
+		},
+		{
+			"long gap",
+			time.Minute,
+			[]Sample{at(0, 0, 100), at(10, 1, 100), at(20, 2, 100), at(500, 3, 100)},
+			[]int{20, 500},
+		},
+		{
+			"default window",
+			0,
+			[]Sample{at(0, 0, 100), at(60, 1, 100), at(120, 2, 100), at(150, 3, 100)},
+			[]int{0, 60, 120, 150},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := Tracker{Window: tt.window}
+			for _, s := range tt.add {
+				tr.Add(s)
+			}
+			var got []int
+			for _, s := range tr.samples {
+				got = append(got, int(s.Time.Sub(t0)/time.Second))
+			}
+			if !reflect.DeepEqual(got, tt.wantSec) {
+				t.Errorf("samples kept at %v s, want %v s", got, tt.wantSec)
+			}
+			if last, ok := tr.Last(); !ok || last.Time != tt.add[len(tt.add)-1].Time {
+				t.Errorf("Last = %v, %v, want the newest sample", last.Time, ok)
+			}
+		})
+	}
+}
+
+func TestTrackerRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		rate    float64
+		eta     time.Duration
+		etaOK   bool
+	}{
+		{"no samples", nil, 0, 0, false},
+		{"one sample", []Sample{at(0, 100, 1000)}, 0, 0, false},
+		{"steady", []Sample{at(0, 100, 1000), at(10, 200, 1000), at(20, 300, 1000)}, 10, 70 * time.Second, true},
+		{"rate over the whole window", []Sample{at(0, 0, 1000), at(10, 400, 1000), at(40, 400, 1000)}, 10, 60 * time.Second, true},
+		// a snap sync downloads state before it imports any block
+		{"snap sync without blocks", []Sample{at(0, 0, 15000000), at(30, 0, 15000000), at(60, 0, 15000000)}, 0, 0, false},
+		{"head went back", []Sample{at(0, 500, 1000), at(10, 400, 1000)}, 0, 0, false},
+		{"same time", []Sample{at(0, 100, 1000), at(0, 200, 1000)}, 0, 0, false},
+		{"synced", []Sample{synced(0, 1000), synced(12, 1001)}, 1.0 / 12, 0, false},
+		{"done", []Sample{at(0, 900, 1000), at(10, 1000, 1000)}, 10, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := Tracker{Window: time.Hour}
+			for _, s := range tt.samples {
+				tr.Add(s)
+			}
+			if got := tr.BlocksPerSecond(); got != tt.rate {
+				t.Errorf("BlocksPerSecond = %v, want %v", got, tt.rate)
+			}
+			eta, ok := tr.ETA()
+			if eta != tt.eta || ok != tt.etaOK {
+				t.Errorf("ETA = %v, %v, want %v, %v", eta, ok, tt.eta, tt.etaOK)
+			}
+		})
+	}
+}
+
+func TestTrackerReset(t *testing.T) {
+	var tr Tracker
+	tr.Add(at(0, 0, 100))
+	tr.Add(at(10, 50, 100))
+	tr.Reset()
+	if _, ok := tr.Last(); ok {
+		t.Error("Last after Reset found a sample")
+	}
+	if rate := tr.BlocksPerSecond(); rate != 0 {
+		t.Errorf("BlocksPerSecond after Reset = %v, want 0", rate)
+	}
+}